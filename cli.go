@@ -0,0 +1,656 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"app-launcher/config"
+	"app-launcher/control"
+	"app-launcher/executor"
+	"app-launcher/history"
+	"app-launcher/logger"
+	"app-launcher/pathindex"
+)
+
+// Exit codes of the command-line subcommands
+const (
+	exitOK    = 0 // The subcommand succeeded
+	exitError = 1 // The configuration is invalid or the command failed
+	exitUsage = 2 // The subcommand was used incorrectly
+)
+
+// subcommands lists the headless subcommands with a one-line description
+var subcommands = []struct {
+	name  string
+	usage string
+	help  string
+}{
+	{"validate", "validate [--json] [--schema]", "Check the configuration and report every error, or print its JSON Schema"},
+	{"list", "list [--json] [--all]", "Print all configured commands"},
+	{"run", "run <name> [args...]", "Launch a command without opening the window"},
+	{"which", "which <name> [args...]", "Print the resolved path, arguments and environment of a command"},
+	{"ps", "ps [--json]", "List the processes started by the running launcher"},
+	{"kill", "kill <name|pid>", "Terminate processes started by the running launcher"},
+	{"convert", "convert [--to FORMAT] [--force] <input> [output]", "Translate a configuration between JSON, TOML and YAML"},
+	{"init", "init [--format FORMAT] [--force]", "Write a starter configuration to the first writable location"},
+}
+
+// cli runs one headless subcommand. None of the subcommands touch the GUI,
+// so they work on machines without a display (CI, dotfile checks, scripts).
+type cli struct {
+	configPath string
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// runCLI runs the subcommand named by args[0] and returns the process exit code.
+// configPath is the configuration given on the main command line; each subcommand
+// also accepts its own --config flag.
+func runCLI(configPath string, args []string, stdout, stderr io.Writer) int {
+	c := &cli{configPath: configPath, stdout: stdout, stderr: stderr}
+
+	if len(args) == 0 {
+		c.usage()
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "validate":
+		return c.validate(args[1:])
+	case "list":
+		return c.list(args[1:])
+	case "run":
+		return c.run(args[1:])
+	case "which":
+		return c.which(args[1:])
+	case "ps":
+		return c.ps(args[1:])
+	case "kill":
+		return c.kill(args[1:])
+	case "convert":
+		return c.convert(args[1:])
+	case "init":
+		return c.init(args[1:])
+	default:
+		fmt.Fprintf(stderr, "launcher: unknown subcommand %q\n", args[0])
+		c.usage()
+		return exitUsage
+	}
+}
+
+// usage prints the list of subcommands
+func (c *cli) usage() {
+	fmt.Fprintln(c.stderr, "Subcommands:")
+	w := tabwriter.NewWriter(c.stderr, 0, 0, 2, ' ', 0)
+	for _, sub := range subcommands {
+		fmt.Fprintf(w, "  launcher %s\t%s\n", sub.usage, sub.help)
+	}
+	w.Flush()
+}
+
+// flagSet creates the flag set shared by all subcommands.
+// Logging is silenced unless --verbose is given, so that output stays machine-readable.
+func (c *cli) flagSet(name string) (*flag.FlagSet, *bool) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	fs.StringVar(&c.configPath, "config", c.configPath, "Path to configuration file")
+	verbose := fs.Bool("verbose", false, "Write log messages to stderr")
+	return fs, verbose
+}
+
+// parse parses the subcommand flags and applies --verbose
+func (c *cli) parse(fs *flag.FlagSet, verbose *bool, args []string) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if *verbose {
+		logger.SetOutput(os.Stderr)
+	} else {
+		logger.SetOutput(io.Discard)
+	}
+	return true
+}
+
+// load loads the configuration, printing every error on failure
+func (c *cli) load() (*config.ConfigManager, bool) {
+	configManager, err := config.NewConfigManager(c.configPath)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return nil, false
+	}
+	if err := configManager.Load(); err != nil {
+		c.printErrors(c.configPath, err)
+		return nil, false
+	}
+	return configManager, true
+}
+
+// printErrors prints every problem in err, one per line. Problems that do not
+// name their file are attributed to path.
+func (c *cli) printErrors(path string, err error) {
+	for _, e := range config.AsValidationErrors(err) {
+		if e.File == "" {
+			e.File = path
+		}
+		fmt.Fprintln(c.stderr, e)
+	}
+}
+
+// validateResult is the JSON representation of the outcome of validate
+type validateResult struct {
+	File     string                  `json:"file"`
+	Valid    bool                    `json:"valid"`
+	Commands int                     `json:"commands"`
+	Errors   config.ValidationErrors `json:"errors"`
+	Warnings config.ValidationErrors `json:"warnings"`
+}
+
+// validate checks the configuration and reports every error found
+func (c *cli) validate(args []string) int {
+	fs, verbose := c.flagSet("validate")
+	asJSON := fs.Bool("json", false, "Print the result as a JSON object")
+	schema := fs.Bool("schema", false, "Print the JSON Schema of configuration files instead")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher validate [--json] [--schema]")
+		return exitUsage
+	}
+
+	if *schema {
+		data, err := config.Schema()
+		if err != nil {
+			fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+			return exitError
+		}
+		c.stdout.Write(data)
+		return exitOK
+	}
+
+	if !*asJSON {
+		configManager, ok := c.load()
+		if !ok {
+			return exitError
+		}
+		for _, w := range configManager.Warnings() {
+			fmt.Fprintf(c.stderr, "warning: %v\n", w)
+		}
+		fmt.Fprintf(c.stdout, "%s: OK (%d commands)\n", c.configPath, len(configManager.AllCommandNames()))
+		return exitOK
+	}
+
+	result := validateResult{File: c.configPath, Errors: config.ValidationErrors{}, Warnings: config.ValidationErrors{}}
+	configManager, err := config.NewConfigManager(c.configPath)
+	if err == nil {
+		err = configManager.Load()
+	}
+	if err != nil {
+		result.Errors = config.AsValidationErrors(err)
+	} else {
+		result.Valid = true
+		result.Commands = len(configManager.AllCommandNames())
+		if warnings := configManager.Warnings(); len(warnings) > 0 {
+			result.Warnings = warnings
+		}
+	}
+
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+	if !result.Valid {
+		return exitError
+	}
+	return exitOK
+}
+
+// listEntry is the JSON representation of a command printed by list
+type listEntry struct {
+	Name string `json:"name"`
+	config.Command
+}
+
+// list prints all configured commands as a table or as JSON
+func (c *cli) list(args []string) int {
+	fs, verbose := c.flagSet("list")
+	asJSON := fs.Bool("json", false, "Print commands as a JSON array")
+	all := fs.Bool("all", false, "Include hidden commands")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher list [--json] [--all]")
+		return exitUsage
+	}
+
+	configManager, ok := c.load()
+	if !ok {
+		return exitError
+	}
+
+	names := configManager.CommandNames()
+	if *all {
+		names = configManager.AllCommandNames()
+	}
+	entries := []listEntry{}
+	for _, name := range names {
+		cmd, _ := configManager.GetCommand(name)
+		entries = append(entries, listEntry{Name: name, Command: cmd})
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDESCRIPTION\tTAGS\tPATH\tARGS")
+	for _, entry := range entries {
+		tags := make([]string, 0, len(entry.Tags))
+		for _, tag := range entry.Tags {
+			tags = append(tags, "#"+tag)
+		}
+		path := entry.Path
+		if entry.Shell != "" {
+			path = entry.Shell
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", entry.Name, entry.Description, strings.Join(tags, " "), path, joinArgs(entry.Args))
+	}
+	w.Flush()
+	return exitOK
+}
+
+// run launches a command through the Executor and records it in the launch history
+func (c *cli) run(args []string) int {
+	fs, verbose := c.flagSet("run")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher run <name> [args...]")
+		return exitUsage
+	}
+
+	configManager, ok := c.load()
+	if !ok {
+		return exitError
+	}
+
+	exec := executor.NewExecutor(configManager)
+	exec.SetFallback(newPathCommands(configManager))
+	exec.SetRecorder(history.NewStore(history.DefaultPath(c.configPath)))
+	var mu sync.Mutex
+	failed := false
+	exec.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		failed = true
+	})
+	// There is no window, clipboard or notification here, so captured output is printed
+	exec.SetOutputHandler(func(out executor.Output) {
+		mu.Lock()
+		defer mu.Unlock()
+		io.WriteString(c.stdout, out.Stdout)
+		io.WriteString(c.stderr, out.Stderr)
+		if out.Truncated {
+			fmt.Fprintf(c.stderr, "launcher: output of '%s' was truncated\n", out.Name)
+		}
+		if err := out.Err(); err != nil {
+			fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+			failed = true
+		}
+	})
+	if err := exec.Execute(fs.Arg(0), fs.Args()[1:]...); err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+
+	// Macros and output captures run in the background and would end with this process
+	exec.Wait()
+	if failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// socketFlag adds the --socket flag of the subcommands that talk to the running launcher
+func socketFlag(fs *flag.FlagSet) *string {
+	return fs.String("socket", control.DefaultSocketPath(), "Path to the control socket of the running launcher")
+}
+
+// ps lists the processes started by the running launcher, asking it over the control socket
+func (c *cli) ps(args []string) int {
+	fs, verbose := c.flagSet("ps")
+	asJSON := fs.Bool("json", false, "Print processes as a JSON array")
+	socket := socketFlag(fs)
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher ps [--json]")
+		return exitUsage
+	}
+
+	resp, err := control.Send(*socket, control.Request{Action: control.ActionPs})
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+	processes := resp.Processes
+	if processes == nil {
+		processes = []control.Process{}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(c.stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(processes); err != nil {
+			fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tNAME\tSTATUS\tSTARTED\tDURATION\tARGS")
+	for _, p := range processes {
+		status := p.Status
+		if p.Running {
+			status = "running"
+		}
+		duration := time.Duration(p.Duration * float64(time.Second)).Round(time.Second)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", p.PID, p.Name, status, p.Started.Local().Format("15:04:05"), duration, joinArgs(p.Args))
+	}
+	w.Flush()
+	return exitOK
+}
+
+// kill terminates processes started by the running launcher, by command name or PID
+func (c *cli) kill(args []string) int {
+	fs, verbose := c.flagSet("kill")
+	socket := socketFlag(fs)
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprintln(c.stderr, "usage: launcher kill <name|pid>")
+		return exitUsage
+	}
+
+	req := control.Request{Action: control.ActionKill, Command: fs.Arg(0)}
+	if pid, err := strconv.Atoi(fs.Arg(0)); err == nil {
+		req = control.Request{Action: control.ActionKill, PID: pid}
+	}
+	resp, err := control.Send(*socket, req)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+	for _, p := range resp.Processes {
+		fmt.Fprintf(c.stdout, "Killed %s (PID %d)\n", p.Name, p.PID)
+	}
+	return exitOK
+}
+
+// controlProcesses converts processes of the executor for a control response
+func controlProcesses(processes []executor.Process, now time.Time) []control.Process {
+	converted := make([]control.Process, 0, len(processes))
+	for _, p := range processes {
+		converted = append(converted, control.Process{
+			Name:     p.Name,
+			PID:      p.PID,
+			Args:     p.Args,
+			Started:  p.Started,
+			Running:  p.Running(),
+			Status:   p.Status,
+			ExitCode: p.Code,
+			Duration: p.Duration(now).Seconds(),
+		})
+	}
+	return converted
+}
+
+// which prints how a command would be launched without starting it
+func (c *cli) which(args []string) int {
+	fs, verbose := c.flagSet("which")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher which <name> [args...]")
+		return exitUsage
+	}
+
+	configManager, ok := c.load()
+	if !ok {
+		return exitError
+	}
+
+	exec := executor.NewExecutor(configManager)
+	exec.SetFallback(newPathCommands(configManager))
+	if name, ok := exec.CommandName(fs.Arg(0)); ok {
+		if cmd, _ := exec.Command(name); cmd.Steps != nil && fs.NArg() == 1 {
+			c.printSteps(configManager, name, cmd.Steps)
+			return exitOK
+		}
+	}
+	launch, err := exec.Resolve(fs.Arg(0), fs.Args()[1:]...)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+
+	if origin, ok := configManager.Origin(launch.Name); ok {
+		fmt.Fprintf(c.stdout, "from: %s\n", origin)
+	} else {
+		fmt.Fprintln(c.stdout, "from: $PATH")
+	}
+	fmt.Fprintf(c.stdout, "path: %s\n", launch.Path)
+	fmt.Fprintf(c.stdout, "args: %s\n", joinArgs(launch.Args))
+	if launch.Dir != "" {
+		fmt.Fprintf(c.stdout, "cwd:  %s\n", launch.Dir)
+	}
+	if launch.Command.EnvClear {
+		fmt.Fprintln(c.stdout, "env:  (cleared)")
+	}
+	keys := make([]string, 0, len(launch.Command.Env))
+	for key := range launch.Command.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(c.stdout, "env:  %s=%s\n", key, launch.Command.Env[key])
+	}
+	return exitOK
+}
+
+// printSteps prints the steps of a macro for which
+func (c *cli) printSteps(configManager *config.ConfigManager, name string, steps []config.Step) {
+	origin, _ := configManager.Origin(name)
+	fmt.Fprintf(c.stdout, "from: %s\n", origin)
+	for i, step := range steps {
+		var run string
+		switch {
+		case step.Command != "":
+			run = strings.TrimSpace("command " + step.Command + " " + joinArgs(step.Args))
+		case step.Path != "":
+			run = strings.TrimSpace("path " + step.Path + " " + joinArgs(step.Args))
+		default:
+			run = "shell " + strconv.Quote(step.Shell)
+		}
+		var options []string
+		if step.Delay != "" {
+			options = append(options, "delay "+step.Delay)
+		}
+		if step.Wait == config.WaitExit {
+			options = append(options, "wait for exit")
+		}
+		if step.OnError == config.OnErrorContinue {
+			options = append(options, "continue on error")
+		}
+		if len(options) > 0 {
+			run += " (" + strings.Join(options, ", ") + ")"
+		}
+		fmt.Fprintf(c.stdout, "step %d: %s\n", i+1, run)
+	}
+}
+
+// convert translates a configuration file into another format. The input format
+// comes from its extension; the output format from --to or the output extension.
+// Without an output file the result is written to stdout.
+func (c *cli) convert(args []string) int {
+	fs, verbose := c.flagSet("convert")
+	to := fs.String("to", "", "Output format: json, toml or yaml")
+	force := fs.Bool("force", false, "Overwrite an existing output file")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || (fs.NArg() == 1 && *to == "") {
+		fmt.Fprintln(c.stderr, "usage: launcher convert [--to FORMAT] [--force] <input> [output]")
+		fmt.Fprintln(c.stderr, "       --to is required when writing to stdout")
+		return exitUsage
+	}
+	input, output := fs.Arg(0), fs.Arg(1)
+
+	format := config.FormatFromPath(output)
+	if *to != "" {
+		var err error
+		if format, err = config.ParseFormat(*to); err != nil {
+			fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+			return exitUsage
+		}
+	}
+
+	data, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: failed to read config file: %v\n", err)
+		return exitError
+	}
+	cfg, err := config.Decode(data, config.FormatFromPath(input))
+	if err != nil {
+		c.printErrors(input, err)
+		return exitError
+	}
+	converted, err := config.Encode(cfg, format)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: failed to encode config: %v\n", err)
+		return exitError
+	}
+
+	if output == "" {
+		c.stdout.Write(converted)
+		return exitOK
+	}
+	if _, err := os.Stat(output); err == nil && !*force {
+		fmt.Fprintf(c.stderr, "launcher: '%s' already exists (use --force to overwrite)\n", output)
+		return exitError
+	}
+	if err := os.WriteFile(output, converted, 0644); err != nil {
+		fmt.Fprintf(c.stderr, "launcher: failed to write '%s': %v\n", output, err)
+		return exitError
+	}
+	return exitOK
+}
+
+// init writes a starter configuration. With --config the file is written there;
+// otherwise to the first writable directory of the configuration search path.
+func (c *cli) init(args []string) int {
+	fs, verbose := c.flagSet("init")
+	formatName := fs.String("format", "json", "Format of the starter configuration: json, toml or yaml (default: from the --config extension)")
+	force := fs.Bool("force", false, "Overwrite an existing configuration")
+	if !c.parse(fs, verbose, args) {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(c.stderr, "usage: launcher init [--format FORMAT] [--force] [--config PATH]")
+		return exitUsage
+	}
+
+	format, err := config.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitUsage
+	}
+
+	// Only an explicit --config names the target; the default is a search result.
+	// Its extension picks the format unless --format is given.
+	var path string
+	formatSet := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "config":
+			path = c.configPath
+		case "format":
+			formatSet = true
+		}
+	})
+	if path != "" && !formatSet {
+		format = config.FormatFromPath(path)
+	}
+
+	path, err = config.Init(path, format, *force)
+	if err != nil {
+		fmt.Fprintf(c.stderr, "launcher: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(c.stdout, "Wrote starter configuration to %s\n", path)
+	return exitOK
+}
+
+// pathCommands offers the executables on $PATH as long as the configuration enables
+// them, so that changing path_commands takes effect on the next reload
+type pathCommands struct {
+	config *config.ConfigManager
+	index  *pathindex.Index
+}
+
+// newPathCommands creates the $PATH fallback for an executor
+func newPathCommands(configManager *config.ConfigManager) *pathCommands {
+	return &pathCommands{config: configManager, index: pathindex.New(os.Getenv("PATH"))}
+}
+
+// GetCommand returns a command running the named executable on $PATH
+func (p *pathCommands) GetCommand(name string) (config.Command, bool) {
+	if !p.config.PathCommands() {
+		return config.Command{}, false
+	}
+	return p.index.GetCommand(name)
+}
+
+// CommandNames returns the names of the executables on $PATH
+func (p *pathCommands) CommandNames() []string {
+	if !p.config.PathCommands() {
+		return nil
+	}
+	return p.index.CommandNames()
+}
+
+// joinArgs formats arguments for display, quoting those that would otherwise be ambiguous
+func joinArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'\\") {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
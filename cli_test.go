@@ -0,0 +1,571 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"app-launcher/config"
+	"app-launcher/control"
+	"app-launcher/executor"
+)
+
+// writeCLIConfig writes a configuration file into a temporary directory and returns its path
+func writeCLIConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+// TestCLIConvertKeepsEmptyVariantArgs tests that a platform variant clearing the
+// shared arguments with an empty list still clears them after conversion
+func TestCLIConvertKeepsEmptyVariantArgs(t *testing.T) {
+	input := writeCLIConfig(t, `{"commands": {"code": {"path": "/usr/bin/code", "args": ["-n"], "windows": {"path": "C:\\Code\\Code.exe", "args": []}}}}`)
+
+	for _, format := range []string{"toml", "yaml", "json"} {
+		output := filepath.Join(t.TempDir(), "config."+format)
+		if code, _, stderr := runCLIForTest("", "convert", input, output); code != exitOK {
+			t.Fatalf("Failed to convert to %s: %s", format, stderr)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", output, err)
+		}
+		cfg, err := config.Decode(data, config.FormatFromPath(output))
+		if err != nil {
+			t.Fatalf("Failed to decode %s: %v\n%s", format, err, data)
+		}
+		if args := cfg.Commands["code"].Windows.Args; args == nil || len(*args) != 0 {
+			t.Errorf("Expected the empty windows args to survive conversion to %s, got:\n%s", format, data)
+		}
+		input = output
+	}
+}
+
+// runCLIForTest runs a subcommand and returns its exit code and output
+func runCLIForTest(configPath string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := runCLI(configPath, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestCLIValidate(t *testing.T) {
+	valid := writeCLIConfig(t, `{"commands": {"editor": {"path": "/usr/bin/vi", "args": []}}}`)
+	code, stdout, _ := runCLIForTest(valid, "validate")
+	if code != exitOK {
+		t.Errorf("Expected exit code %d, got %d", exitOK, code)
+	}
+	if !strings.Contains(stdout, "OK (1 commands)") {
+		t.Errorf("Unexpected output: %s", stdout)
+	}
+
+	invalid := writeCLIConfig(t, `{"commands": {"a": {"path": ""}, "b": {"path": ""}}}`)
+	code, _, stderr := runCLIForTest(invalid, "validate")
+	if code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+	if !strings.Contains(stderr, "'a'") || !strings.Contains(stderr, "'b'") {
+		t.Errorf("Expected every error to be reported, got: %s", stderr)
+	}
+
+	code, _, _ = runCLIForTest(filepath.Join(t.TempDir(), "missing.json"), "validate")
+	if code != exitError {
+		t.Errorf("Expected exit code %d for a missing file, got %d", exitError, code)
+	}
+}
+
+func TestCLIConfigFlag(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {"editor": {"path": "/usr/bin/vi", "args": []}}}`)
+	code, _, stderr := runCLIForTest("nonexistent.json", "validate", "--config", path)
+	if code != exitOK {
+		t.Errorf("Expected --config to override the default path, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIList(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {
+		"editor": {"path": "/usr/bin/vi", "args": ["-n"]},
+		"browser": {"path": "/usr/bin/firefox", "args": ["--new-window", "my page"]}
+	}}`)
+
+	code, stdout, _ := runCLIForTest(path, "list")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and 2 rows, got: %q", stdout)
+	}
+	if !strings.HasPrefix(lines[1], "browser") || !strings.Contains(lines[1], `"my page"`) {
+		t.Errorf("Unexpected row: %q", lines[1])
+	}
+
+	code, stdout, _ = runCLIForTest(path, "list", "--json")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if len(entries) != 2 || entries[0]["name"] != "browser" || entries[1]["path"] != "/usr/bin/vi" {
+		t.Errorf("Unexpected JSON entries: %v", entries)
+	}
+	if _, exists := entries[0]["allow_args"]; exists {
+		t.Errorf("Expected unset optional fields to be left out, got %v", entries[0])
+	}
+}
+
+func TestCLIListMetadata(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {
+		"editor": {"path": "/usr/bin/vi", "description": "Text editor", "tags": ["dev", "#terminal"]},
+		"secret": {"path": "/usr/bin/secret", "hidden": true}
+	}}`)
+
+	code, stdout, _ := runCLIForTest(path, "list")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d", exitOK, code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected the hidden command to be left out, got: %q", stdout)
+	}
+	for _, want := range []string{"Text editor", "#dev #terminal"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("Row should contain %q, got: %q", want, lines[1])
+		}
+	}
+
+	code, stdout, _ = runCLIForTest(path, "list", "--all")
+	if code != exitOK || !strings.Contains(stdout, "secret") {
+		t.Errorf("Expected --all to include hidden commands, got %d: %s", code, stdout)
+	}
+}
+
+func TestCLIWhich(t *testing.T) {
+	dir := t.TempDir()
+	path := writeCLIConfig(t, `{"commands": {
+		"search": {"path": "/usr/bin/browser", "args": ["https://example.com/?q={query}"], "cwd": "`+filepath.ToSlash(dir)+`", "env": {"LANG": "C"}}
+	}}`)
+
+	code, stdout, stderr := runCLIForTest(path, "which", "search", "go")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	for _, want := range []string{"from: " + path, "args: https://example.com/?q=go", "cwd:  " + dir, "env:  LANG=C"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	code, _, stderr = runCLIForTest(path, "which", "missing")
+	if code != exitError || !strings.Contains(stderr, "not found") {
+		t.Errorf("Expected not found error, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIWhichExpandsEnvironment(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LAUNCHER_TOOLS", dir)
+	path := writeCLIConfig(t, `{"commands": {
+		"tool": {"path": "${LAUNCHER_TOOLS}/tool", "args": ["--profile=$PROFILE", "$$literal"], "cwd": "$LAUNCHER_TOOLS", "env": {"PROFILE": "work"}},
+		"strict": {"path": "$LAUNCHER_UNSET_TOOLS/tool", "args": [], "strict_env": true}
+	}}`)
+
+	code, stdout, stderr := runCLIForTest(path, "which", "tool")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	for _, want := range []string{"path: " + filepath.Join(dir, "tool"), "args: --profile=work $literal", "cwd:  " + dir} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	code, _, stderr = runCLIForTest(path, "which", "strict")
+	if code != exitError || !strings.Contains(stderr, "LAUNCHER_UNSET_TOOLS is not set") {
+		t.Errorf("Expected strict expansion to fail, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIShellCommand(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {
+		"todo": {"shell": "grep -rn {query} . | head", "interpreter": ["/bin/bash", "-c"]}
+	}}`)
+
+	code, stdout, stderr := runCLIForTest(path, "which", "todo", "it's")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	for _, want := range []string{"path: " + filepath.Clean("/bin/bash"), `args: -c "grep -rn 'it'\\''s' . | head"`} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	code, stdout, _ = runCLIForTest(path, "list")
+	if code != exitOK || !strings.Contains(stdout, "grep -rn {query} . | head") {
+		t.Errorf("Expected the shell command in the list, got %d: %s", code, stdout)
+	}
+}
+
+func TestCLIWhichPathCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables need a PATHEXT extension on Windows")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "tool"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write executable: %v", err)
+	}
+	t.Setenv("PATH", bin)
+
+	path := writeCLIConfig(t, `{"commands": {}}`)
+	if code, _, _ := runCLIForTest(path, "which", "tool"); code != exitError {
+		t.Errorf("Expected PATH commands to be off by default, got exit code %d", code)
+	}
+
+	path = writeCLIConfig(t, `{"path_commands": true, "commands": {}}`)
+	code, stdout, stderr := runCLIForTest(path, "which", "tool", "--flag")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	for _, want := range []string{"from: $PATH", "path: " + filepath.Join(bin, "tool"), "args: --flag"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestCLIRunUnknownCommand(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {}}`)
+	code, _, stderr := runCLIForTest(path, "run", "missing")
+	if code != exitError || !strings.Contains(stderr, "not found") {
+		t.Errorf("Expected not found error, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIRunMacro(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+	out := filepath.Join(t.TempDir(), "out")
+	path := writeCLIConfig(t, `{"commands": {
+		"touch": {"path": "/bin/sh", "args": ["-c", "echo touched > `+out+`"]},
+		"chores": {"steps": [
+			{"shell": "sleep 0.1"},
+			{"command": "touch", "delay": "10ms", "wait": "exit"},
+			{"shell": "exit 1", "wait": "exit", "on_error": "continue"}
+		]},
+		"broken": {"steps": [{"shell": "exit 2", "wait": "exit"}, {"command": "touch"}]}
+	}}`)
+
+	code, stdout, stderr := runCLIForTest(path, "which", "chores")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	for _, want := range []string{`step 1: shell "sleep 0.1"`, "step 2: command touch (delay 10ms, wait for exit)", "(wait for exit, continue on error)"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Output should contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	if code, _, stderr := runCLIForTest(path, "run", "chores"); code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	if data, err := os.ReadFile(out); err != nil || string(data) != "touched\n" {
+		t.Errorf("Expected run to wait for the macro, got %q, %v", data, err)
+	}
+
+	code, _, stderr = runCLIForTest(path, "run", "broken")
+	if code != exitError || !strings.Contains(stderr, "macro 'broken' step 1") {
+		t.Errorf("Expected the failing step to be reported, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIRunPrintsOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+	path := writeCLIConfig(t, `{"commands": {
+		"hello": {"shell": "echo hello {1}; echo careful >&2", "output": "clipboard"},
+		"fail": {"shell": "echo no >&2; exit 1", "output": "show"}
+	}}`)
+
+	code, stdout, stderr := runCLIForTest(path, "run", "hello", "world")
+	if code != exitOK || stdout != "hello world\n" || stderr != "careful\n" {
+		t.Errorf("Expected the output to be printed, got %d: %q %q", code, stdout, stderr)
+	}
+
+	code, _, stderr = runCLIForTest(path, "run", "fail")
+	if code != exitError || !strings.Contains(stderr, "no\n") || !strings.Contains(stderr, "command 'fail' failed: exit status 1") {
+		t.Errorf("Expected the failure to be reported, got %d: %s", code, stderr)
+	}
+}
+
+func TestCLIUsageErrors(t *testing.T) {
+	path := writeCLIConfig(t, `{"commands": {}}`)
+	tests := [][]string{
+		{"unknown"},
+		{"run"},
+		{"which"},
+		{"list", "extra"},
+		{"validate", "--bogus"},
+	}
+	for _, args := range tests {
+		if code, _, _ := runCLIForTest(path, args...); code != exitUsage {
+			t.Errorf("Expected exit code %d for %v, got %d", exitUsage, args, code)
+		}
+	}
+}
+
+func TestCLIValidateJSON(t *testing.T) {
+	path := writeCLIConfig(t, "{\"commands\": {\n  \"a\": {\"path\": \"\"}\n}}")
+	code, stdout, _ := runCLIForTest(path, "validate", "--json")
+	if code != exitError {
+		t.Errorf("Expected exit code %d, got %d", exitError, code)
+	}
+
+	var result struct {
+		Valid  bool `json:"valid"`
+		Errors []struct {
+			Command string `json:"command"`
+			Field   string `json:"field"`
+			Line    int    `json:"line"`
+			Column  int    `json:"column"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if result.Valid || len(result.Errors) != 1 {
+		t.Fatalf("Unexpected result: %s", stdout)
+	}
+	e := result.Errors[0]
+	if e.Command != "a" || e.Field != "path" || e.Line != 2 || e.Column != 9 {
+		t.Errorf("Unexpected error entry: %+v", e)
+	}
+}
+
+func TestCLIValidateSchema(t *testing.T) {
+	code, stdout, stderr := runCLIForTest("nonexistent.json", "validate", "--schema")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d without a configuration, got %d: %s", exitOK, code, stderr)
+	}
+	var schema struct {
+		Schema     string                     `json:"$schema"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &schema); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if !strings.Contains(schema.Schema, "json-schema.org") || schema.Properties["commands"] == nil {
+		t.Errorf("Unexpected schema: %s", stdout)
+	}
+}
+
+func TestCLIValidateWarnings(t *testing.T) {
+	path := writeCLIConfig(t, `{"lenient": true, "commands": {"editor": {"path": "/usr/bin/vi", "colour": "blue"}}}`)
+	code, stdout, stderr := runCLIForTest(path, "validate")
+	if code != exitOK || !strings.Contains(stdout, "OK (1 commands)") {
+		t.Fatalf("Expected lenient configuration to validate, got %d: %s%s", code, stdout, stderr)
+	}
+	if !strings.Contains(stderr, "warning: ") || !strings.Contains(stderr, "unknown field 'colour'") {
+		t.Errorf("Expected a warning about the unknown field, got: %s", stderr)
+	}
+
+	code, stdout, _ = runCLIForTest(path, "validate", "--json")
+	var result struct {
+		Valid    bool `json:"valid"`
+		Warnings []struct {
+			Command string `json:"command"`
+			Message string `json:"message"`
+		} `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if code != exitOK || !result.Valid || len(result.Warnings) != 1 || result.Warnings[0].Command != "editor" {
+		t.Errorf("Unexpected result: %s", stdout)
+	}
+}
+
+func TestCLIConvert(t *testing.T) {
+	input := writeCLIConfig(t, `{"commands": {"editor": {"path": "C:\\Tools\\vi.exe", "args": ["-n"], "env": {"LANG": "C"}}}}`)
+	output := filepath.Join(t.TempDir(), "config.yaml")
+
+	code, _, stderr := runCLIForTest("", "convert", input, output)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	code, stdout, stderr := runCLIForTest("", "convert", "--to", "json", output)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	original, _ := os.ReadFile(input)
+	want, _ := config.Decode(original, config.FormatJSON)
+	got, err := config.Decode([]byte(stdout), config.FormatJSON)
+	if err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, stdout)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Round trip changed the configuration:\n got: %v\nwant: %v", got, want)
+	}
+
+	if code, _, _ := runCLIForTest("", "convert", input, output); code != exitError {
+		t.Errorf("Expected existing output to be kept without --force, got %d", code)
+	}
+	if code, _, _ := runCLIForTest("", "convert", input); code != exitUsage {
+		t.Errorf("Expected usage error without output or --to, got %d", code)
+	}
+}
+
+func TestCLIInit(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("LAUNCHER_CONFIG", "")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", t.TempDir())
+
+	code, stdout, stderr := runCLIForTest("ignored.json", "init", "--format", "toml")
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	path := filepath.Join(configHome, "launcher", "config.toml")
+	if !strings.Contains(stdout, path) {
+		t.Errorf("Expected output to name %s, got: %s", path, stdout)
+	}
+	if code, _, stderr := runCLIForTest(path, "validate"); code != exitOK {
+		t.Errorf("Starter configuration is invalid: %s", stderr)
+	}
+	if code, _, _ := runCLIForTest("ignored.json", "init"); code != exitError {
+		t.Errorf("Expected init to refuse overwriting, got %d", code)
+	}
+
+	explicit := filepath.Join(t.TempDir(), "mine.json")
+	if code, _, stderr := runCLIForTest("ignored.json", "init", "--config", explicit); code != exitOK {
+		t.Errorf("Expected init to write to --config, got %d: %s", code, stderr)
+	}
+	if _, err := os.Stat(explicit); err != nil {
+		t.Errorf("Expected %s to be written: %v", explicit, err)
+	}
+}
+
+// processHandler is a control.Handler serving a fixed process list
+type processHandler struct {
+	processes []control.Process
+}
+
+func (h *processHandler) RunCommand(name string, args []string) error { return nil }
+func (h *processHandler) Show()                                       {}
+func (h *processHandler) Hide()                                       {}
+func (h *processHandler) Toggle()                                     {}
+func (h *processHandler) Reload() error                               { return nil }
+func (h *processHandler) List() []string                              { return nil }
+func (h *processHandler) Processes() []control.Process                { return h.processes }
+
+func (h *processHandler) Kill(target string) ([]control.Process, error) {
+	var killed []control.Process
+	for _, p := range h.processes {
+		if p.Running && (p.Name == target || strconv.Itoa(p.PID) == target) {
+			killed = append(killed, p)
+		}
+	}
+	if len(killed) == 0 {
+		return nil, fmt.Errorf("no running process of command '%s'", target)
+	}
+	return killed, nil
+}
+
+// startControlServer serves handler on a socket in a private directory and returns its path
+func startControlServer(t *testing.T, handler control.Handler) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain socket tests are not run on Windows")
+	}
+	dir, err := os.MkdirTemp("", "ctl")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	server, err := control.NewServer(filepath.Join(dir, control.SocketName), handler)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return filepath.Join(dir, control.SocketName)
+}
+
+func TestCLIPsAndKill(t *testing.T) {
+	started := time.Date(2026, 1, 2, 9, 30, 0, 0, time.Local)
+	socket := startControlServer(t, &processHandler{processes: []control.Process{
+		{Name: "code", PID: 4242, Args: []string{"-n", "~/src"}, Started: started, Running: true, Duration: 90},
+		{Name: "build", PID: 17, Started: started, Status: "exit status 2", ExitCode: 2, Duration: 3.2},
+	}})
+
+	code, stdout, stderr := runCLIForTest("", "ps", "--socket", socket)
+	if code != exitOK {
+		t.Fatalf("Expected exit code %d, got %d: %s", exitOK, code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "PID") {
+		t.Fatalf("Unexpected ps output:\n%s", stdout)
+	}
+	for i, want := range [][]string{{"4242", "code", "running", "09:30:00", "1m30s", "-n ~/src"}, {"17", "build", "exit status 2", "3s"}} {
+		for _, field := range want {
+			if !strings.Contains(lines[i+1], field) {
+				t.Errorf("Line %q should contain %q", lines[i+1], field)
+			}
+		}
+	}
+
+	code, stdout, _ = runCLIForTest("", "ps", "--json", "--socket", socket)
+	var processes []control.Process
+	if err := json.Unmarshal([]byte(stdout), &processes); err != nil || code != exitOK || len(processes) != 2 {
+		t.Errorf("Unexpected JSON output (%v):\n%s", err, stdout)
+	}
+
+	for _, target := range []string{"code", "4242"} {
+		code, stdout, stderr = runCLIForTest("", "kill", "--socket", socket, target)
+		if code != exitOK || !strings.Contains(stdout, "Killed code (PID 4242)") {
+			t.Errorf("Expected kill %s to succeed, got %d: %s%s", target, code, stdout, stderr)
+		}
+	}
+	code, _, stderr = runCLIForTest("", "kill", "--socket", socket, "build")
+	if code != exitError || !strings.Contains(stderr, "no running process") {
+		t.Errorf("Expected kill of an exited command to fail, got %d: %s", code, stderr)
+	}
+
+	code, _, stderr = runCLIForTest("", "ps", "--socket", filepath.Join(t.TempDir(), "none.sock"))
+	if code != exitError || !strings.Contains(stderr, "not running") {
+		t.Errorf("Expected an error without a running launcher, got %d: %s", code, stderr)
+	}
+}
+
+func TestControlProcesses(t *testing.T) {
+	started := time.Now().Add(-time.Minute)
+	converted := controlProcesses([]executor.Process{
+		{Name: "code", PID: 1, Args: []string{"-n"}, Started: started},
+		{Name: "make", PID: 2, Started: started, Ended: started.Add(2 * time.Second), Status: "exit status 1", Code: 1},
+	}, started.Add(time.Minute))
+
+	if !converted[0].Running || converted[0].Duration != 60 || converted[0].Args[0] != "-n" {
+		t.Errorf("Unexpected running process: %+v", converted[0])
+	}
+	if converted[1].Running || converted[1].Duration != 2 || converted[1].ExitCode != 1 || converted[1].Status != "exit status 1" {
+		t.Errorf("Unexpected exited process: %+v", converted[1])
+	}
+}
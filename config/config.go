@@ -2,11 +2,24 @@ package config
 
 import (
 	"app-launcher/logger"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fsnotify/fsnotify"
 )
 
+// reloadDebounce is how long the watcher waits after the last file event before
+// reloading, so that editors which write a file in several steps trigger one reload.
+const reloadDebounce = 100 * time.Millisecond
+
 // Command represents a single command configuration with path and arguments.
 //
 // Configuration Format:
@@ -32,14 +45,73 @@ import (
 //     converted to backslashes (\) on Windows for compatibility.
 //   - Args: Array of command-line arguments to pass to the application.
 //     Can be empty ([]) if no arguments are needed.
+//   - Shell: A command line run by a shell instead of Path and Args, so pipes, redirects
+//     and && work. Placeholders are replaced by quoted values and typed arguments are
+//     appended quoted, so they cannot inject shell syntax. Environment variables are
+//     left to the shell.
+//   - Interpreter: Program and flags that run Shell, e.g. ["pwsh", "-Command"]. Defaults to
+//     ["/bin/sh", "-c"], or ["cmd.exe", "/C"] on Windows.
+//   - AllowArgs: Whether arguments typed after the command name in the launcher
+//     are appended to Args. Defaults to false, in which case extra arguments are rejected.
+//   - Cwd: Working directory for the process. Relative paths are resolved against the
+//     directory of the configuration file and a leading ~ against the home directory.
+//     Defaults to the launcher's own working directory.
+//   - Env: Environment variables to set for the process, merged over the launcher's environment.
+//   - EnvClear: Start from an empty environment instead of the launcher's, so only Env is set.
+//   - StrictEnv: Fail the launch when Path, Args or Cwd refer to an unset environment
+//     variable ($NAME, ${NAME} or %NAME%) instead of expanding it leniently.
+//   - Aliases: Further names the command can be launched by, e.g. "ch" for "chrome".
+//     An alias may not collide with another command or alias.
+//   - Description: Short text shown next to the command in the launcher and in listings.
+//   - Tags: Keywords to find the command by, e.g. "dev". Typing "#dev" in the launcher
+//     lists the commands tagged "dev". A leading "#" in the configuration is dropped.
+//   - Icon: Image file shown next to the command. Relative paths are resolved like Cwd.
+//   - Hidden: Leave the command out of listings and results; it still runs by its exact name.
+//   - SingleInstance: What launching the command does while a process the launcher started
+//     for it is still running: "ignore" does nothing, "notify" reports that it is already
+//     running and "activate" runs the command named by Activate, whose path and args may
+//     use {pid} for the running process. Empty starts another process.
+//   - Activate: Command run instead of a second instance, e.g. one that focuses its window.
+//   - Output: Capture what the process writes instead of discarding it and, once it
+//     has exited, "show" it in the launcher window, copy it to the "clipboard" or
+//     "notify" it as a desktop notification. For quick utilities such as uuidgen.
+//   - Timeout: How long to wait for a command with Output before it is killed, e.g.
+//     "30s". Defaults to 10 seconds.
+//   - Steps: Makes the command a macro that runs these steps in order instead of a single
+//     process (see Step). A macro has no Path, Shell or Args; its Cwd, Env and
+//     Interpreter apply to the steps that run a path or shell command.
+//   - Linux, Windows, Darwin: Platform variants overriding Path, Args, Shell, Cwd and Env on that
+//     operating system (see PlatformOverride). A command with variants only exists on the
+//     platforms it has a variant for.
 type Command struct {
-	Path string   `json:"path"` // Absolute path to executable
-	Args []string `json:"args"` // Command-line arguments (can be empty)
+	Path           string            `json:"path" toml:"path" yaml:"path"`                                                                // Absolute path to executable
+	Args           []string          `json:"args" toml:"args" yaml:"args"`                                                                // Command-line arguments (can be empty)
+	Shell          string            `json:"shell,omitempty" toml:"shell,omitempty" yaml:"shell,omitempty"`                               // Command line run by a shell instead of path
+	Interpreter    []string          `json:"interpreter,omitempty" toml:"interpreter,omitempty" yaml:"interpreter,omitempty"`             // Shell that runs it
+	AllowArgs      bool              `json:"allow_args,omitempty" toml:"allow_args,omitempty" yaml:"allow_args,omitempty"`                // Accept user-typed arguments
+	Cwd            string            `json:"cwd,omitempty" toml:"cwd,omitempty" yaml:"cwd,omitempty"`                                     // Working directory
+	Env            map[string]string `json:"env,omitempty" toml:"env,omitempty" yaml:"env,omitempty"`                                     // Extra environment variables
+	EnvClear       bool              `json:"env_clear,omitempty" toml:"env_clear,omitempty" yaml:"env_clear,omitempty"`                   // Do not inherit the launcher's environment
+	StrictEnv      bool              `json:"strict_env,omitempty" toml:"strict_env,omitempty" yaml:"strict_env,omitempty"`                // Unset variables fail the launch
+	Aliases        []string          `json:"aliases,omitempty" toml:"aliases,omitempty" yaml:"aliases,omitempty"`                         // Alternative names
+	Description    string            `json:"description,omitempty" toml:"description,omitempty" yaml:"description,omitempty"`             // Text shown next to the name
+	Tags           []string          `json:"tags,omitempty" toml:"tags,omitempty" yaml:"tags,omitempty"`                                  // Keywords, searchable as #tag
+	Icon           string            `json:"icon,omitempty" toml:"icon,omitempty" yaml:"icon,omitempty"`                                  // Image file shown next to the name
+	Hidden         bool              `json:"hidden,omitempty" toml:"hidden,omitempty" yaml:"hidden,omitempty"`                            // Not listed, only run by exact name
+	SingleInstance string            `json:"single_instance,omitempty" toml:"single_instance,omitempty" yaml:"single_instance,omitempty"` // Policy while already running
+	Activate       string            `json:"activate,omitempty" toml:"activate,omitempty" yaml:"activate,omitempty"`                      // Command run instead of a second instance
+	Output         string            `json:"output,omitempty" toml:"output,omitempty" yaml:"output,omitempty"`                            // Where captured output goes
+	Timeout        string            `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`                         // Limit for commands with output
+	Steps          []Step            `json:"steps,omitempty" toml:"steps,omitempty" yaml:"steps,omitempty"`                               // Steps of a macro
+	Linux          *PlatformOverride `json:"linux,omitempty" toml:"linux,omitempty" yaml:"linux,omitempty"`                               // Overrides on Linux
+	Windows        *PlatformOverride `json:"windows,omitempty" toml:"windows,omitempty" yaml:"windows,omitempty"`                         // Overrides on Windows
+	Darwin         *PlatformOverride `json:"darwin,omitempty" toml:"darwin,omitempty" yaml:"darwin,omitempty"`                            // Overrides on macOS
 }
 
 // Config represents the root configuration structure.
 //
-// The configuration file must be valid JSON with a "commands" object at the root.
+// The configuration file must be valid JSON, TOML or YAML (see FormatFromPath) with a
+// "commands" object at the root.
 // Each key in "commands" is the command name that users will type in the launcher,
 // and the value is a Command object specifying the executable path and arguments.
 //
@@ -49,17 +121,72 @@ type Command struct {
 //
 // Validation Rules:
 //   - Command names must be non-empty strings
-//   - Each command must have a non-empty "path" field
-//   - The "args" field can be empty but must be present
-//   - Duplicate command names are not allowed (enforced by JSON object structure)
+//   - Each command must have a non-empty "path" field, or a "shell" command or "steps" instead
+//   - The "args" field can be empty but must be present, and empty with "shell"
+//   - Duplicate command names are rejected, reporting every definition. Setting
+//     "allow_duplicates": true at the root keeps the last definition instead
+//
+// Includes:
+// "include" lists further configuration files, or glob patterns such as
+// "~/.config/launcher/conf.d/*.json", relative to the including file. A command
+// defined in several files is taken from the file with the highest precedence
+// (see includeLoader); the main file always wins.
+//
+// Desktop Entries:
+// "desktop" imports installed applications from .desktop files (see DesktopImport).
+// The section of the file with the highest precedence applies.
+//
+// Name Lookup:
+// Names typed in the launcher are trimmed of surrounding whitespace and may be a
+// command name or one of its aliases. Setting "case_insensitive": true in any file
+// also ignores case, in which case names and aliases must differ in more than case.
+//
+// Strict Environment:
+// "strict_env": true at the root sets strict_env on every command defined in that file.
+//
+// Shell Commands:
+// "interpreter" at the root is the interpreter of the shell commands defined in that
+// file that do not set their own.
+//
+// PATH Commands:
+// Setting "path_commands": true in any file offers every executable in the directories
+// of $PATH as a command, for names that no configured command uses.
+//
+// Schema:
+// Every file is checked against the JSON Schema generated from these types (see
+// Schema): values must have the right type, a command needs a path, a shell command,
+// steps or a platform variant, and unknown fields are rejected. Setting "lenient": true at the root
+// reports unknown fields in that file as warnings instead (see Warnings).
 type Config struct {
-	Commands map[string]Command `json:"commands"`
+	Include         []string           `json:"include,omitempty" toml:"include,omitempty" yaml:"include,omitempty"` // Further files or glob patterns to load
+	Commands        map[string]Command `json:"commands" toml:"commands" yaml:"commands"`
+	AllowDuplicates bool               `json:"allow_duplicates,omitempty" toml:"allow_duplicates,omitempty" yaml:"allow_duplicates,omitempty"` // Keep the last of duplicate commands
+	Desktop         *DesktopImport     `json:"desktop,omitempty" toml:"desktop,omitempty" yaml:"desktop,omitempty"`                            // Import applications from .desktop files
+	PathCommands    bool               `json:"path_commands,omitempty" toml:"path_commands,omitempty" yaml:"path_commands,omitempty"`          // Offer executables on $PATH
+	CaseInsensitive bool               `json:"case_insensitive,omitempty" toml:"case_insensitive,omitempty" yaml:"case_insensitive,omitempty"` // Ignore case when looking up names
+	StrictEnv       bool               `json:"strict_env,omitempty" toml:"strict_env,omitempty" yaml:"strict_env,omitempty"`                   // Set strict_env on every command of the file
+	Interpreter     []string           `json:"interpreter,omitempty" toml:"interpreter,omitempty" yaml:"interpreter,omitempty"`                // Default interpreter of the file's shell commands
+	Lenient         bool               `json:"lenient,omitempty" toml:"lenient,omitempty" yaml:"lenient,omitempty"`                            // Only warn about unknown fields in the file
 }
 
-// ConfigManager handles loading and accessing configuration
+// ConfigManager handles loading and accessing configuration.
+//
+// The command map is replaced as a whole on every successful Load, so readers
+// always see either the previous or the new configuration, never a mix of both.
 type ConfigManager struct {
 	configPath string
-	commands   map[string]Command
+
+	mu       sync.RWMutex
+	commands map[string]Command
+	origins  map[string]string // File that defined each command
+	names    *nameTable        // Names and aliases to look commands up by
+	pathCmds bool              // Executables on $PATH are offered as commands
+	sources  []string          // Files read by the last successful Load, to watch for changes
+	warnings ValidationErrors  // Problems the last successful Load tolerated
+	patterns []string          // Include patterns of the last successful Load, to watch for new files
+
+	watcher *fsnotify.Watcher
+	timer   *time.Timer
 }
 
 // NewConfigManager creates a new ConfigManager with the specified config file path
@@ -74,10 +201,15 @@ func NewConfigManager(configPath string) (*ConfigManager, error) {
 	return &ConfigManager{
 		configPath: configPath,
 		commands:   make(map[string]Command),
+		origins:    make(map[string]string),
+		names:      newNameTable(false),
 	}, nil
 }
 
-// Load reads and parses the JSON configuration file
+// Load reads and parses the configuration file in the format given by its extension
+// (see FormatFromPath).
+// Every problem is reported: the returned error is a ValidationErrors sorted by position.
+// If the file cannot be read or fails validation, the previously loaded commands stay active.
 func (c *ConfigManager) Load() error {
 	logger.Info("Loading configuration from: %s", c.configPath)
 
@@ -88,52 +220,541 @@ func (c *ConfigManager) Load() error {
 		return fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse JSON
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		logger.Error("Failed to parse configuration file '%s': %v", c.configPath, err)
-		return fmt.Errorf("failed to parse config file: %w", err)
+	// Parse the file and everything it includes
+	absPath, err := filepath.Abs(c.configPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve config path: %w", err)
 	}
+	loader := newIncludeLoader()
+	loader.load(absPath, data, nil)
 
-	// Validate and store commands
-	if cfg.Commands == nil {
-		err := fmt.Errorf("configuration must contain 'commands' field")
-		logger.Error("Invalid configuration structure in '%s': %v", c.configPath, err)
-		return err
+	loader.warnings.sort()
+	for _, w := range loader.warnings {
+		logger.Warn("Configuration warning: %v", w)
 	}
 
-	for name, cmd := range cfg.Commands {
-		// Validate command name
-		if name == "" {
-			err := fmt.Errorf("command name cannot be empty")
-			logger.Error("Configuration validation failed: %v", err)
-			return err
+	if len(loader.errs) > 0 {
+		loader.errs.sort()
+		for _, e := range loader.errs {
+			logger.Error("Failed to parse configuration: %v", e)
 		}
+		return loader.errs
+	}
 
-		// Validate required fields
-		if cmd.Path == "" {
-			err := fmt.Errorf("command '%s' must have a non-empty path", name)
-			logger.Error("Configuration validation failed: %v", err)
-			return err
+	// Validate every command of every file so that all problems are reported at once.
+	// Later layers override earlier ones (see includeLoader).
+	commands := make(map[string]Command)
+	origins := make(map[string]string)
+	defined := make(map[string]layer)
+	caseInsensitive := false
+	var errs ValidationErrors
+	for _, l := range loader.layers {
+		caseInsensitive = caseInsensitive || l.cfg.CaseInsensitive
+		problems, unavailable := c.validateLayer(l)
+		if len(problems) > 0 {
+			errs = append(errs, problems...)
+			continue
+		}
+		// A command limited to other platforms also hides what it overrides
+		for _, name := range unavailable {
+			logger.Info("Command '%s' from '%s' has no variant for %s and is not available", name, l.path, currentOS)
+			delete(commands, name)
+			delete(origins, name)
+			delete(defined, name)
 		}
+		for name, cmd := range l.cfg.Commands {
+			if origin, exists := origins[name]; exists {
+				logger.Info("Command '%s' from '%s' overrides the definition in '%s'", name, l.path, origin)
+			}
+			commands[name] = cmd
+			origins[name] = l.path
+			defined[name] = l
+		}
+	}
 
-		// Args can be nil or empty, but if present must be a valid slice
-		if cmd.Args == nil {
-			cmd.Args = []string{}
+	// Names and aliases can only be checked once every file has been merged
+	names, problems := buildNameTable(commands, defined, caseInsensitive)
+	errs = append(errs, problems...)
+
+	var desktopPatterns []string
+	if len(problems) == 0 {
+		// Imported applications have the lowest precedence
+		if settings := desktopSettings(loader.layers); settings != nil && settings.Enabled {
+			imported, importedOrigins, patterns := importDesktop(settings)
+			for name, cmd := range imported {
+				if owner, exists := names.resolve(name); exists {
+					logger.Info("Command '%s' from '%s' overrides the desktop entry '%s'", owner, origins[owner], importedOrigins[name])
+					continue
+				}
+				names.add(name, name, "desktop entry "+importedOrigins[name])
+				// The localized name is only a convenience, so any other command keeps it
+				if len(cmd.Aliases) > 0 {
+					if owner, ok := names.add(cmd.Aliases[0], name, "name of desktop entry "+importedOrigins[name]); !ok {
+						logger.Info("Desktop entry '%s' cannot be launched as '%s', which is taken by %s", importedOrigins[name], cmd.Aliases[0], owner)
+						cmd.Aliases = nil
+					}
+				}
+				commands[name] = cmd
+				origins[name] = importedOrigins[name]
+			}
+			desktopPatterns = patterns
 		}
 
-		c.commands[name] = cmd
+		// Steps and activate commands may name any command, imported ones included
+		errs = append(errs, resolveActivate(commands, defined, names)...)
+		errs = append(errs, resolveSteps(commands, defined, names)...)
+	}
+	if len(errs) > 0 {
+		errs.sort()
+		for _, e := range errs {
+			logger.Error("Configuration validation failed: %v", e)
+		}
+		return errs
+	}
+
+	pathCommands := false
+	for _, l := range loader.layers {
+		pathCommands = pathCommands || l.cfg.PathCommands
 	}
 
-	logger.Info("Successfully loaded %d commands from configuration", len(c.commands))
+	// Swap in the new command map and the files it came from only after every file has
+	// been validated; a failed load keeps watching the files of the previous one
+	c.mu.Lock()
+	c.commands = commands
+	c.origins = origins
+	c.names = names
+	c.pathCmds = pathCommands
+	c.sources = loader.sources
+	c.patterns = append(loader.patterns, desktopPatterns...)
+	c.warnings = loader.warnings
+	c.mu.Unlock()
+
+	logger.Info("Successfully loaded %d commands from %d configuration files", len(commands), len(loader.layers))
 	return nil
 }
 
-// GetCommand retrieves a command by name with O(1) lookup
+// validateLayer checks the commands of one configuration file, normalizing them in place.
+// Every problem is returned with the file and its position filled in. Commands without a
+// variant for this platform are removed from the file and returned as unavailable.
+func (c *ConfigManager) validateLayer(l layer) (errs ValidationErrors, unavailable []string) {
+	if l.cfg.Commands == nil && len(l.cfg.Include) == 0 && l.cfg.Desktop == nil && !l.cfg.PathCommands {
+		errs = append(errs, &ValidationError{Field: "commands", Message: "configuration must contain 'commands' field"})
+		errs[0].Line, errs[0].Column = l.positions.lookup()
+	}
+	if l.cfg.Interpreter != nil && !validInterpreter(l.cfg.Interpreter) {
+		errs = append(errs, &ValidationError{Field: "interpreter", Message: "interpreter must start with a program", path: []string{"interpreter"}})
+	}
+
+	for name, cmd := range l.cfg.Commands {
+		if problems := duplicateErrors(l.positions, name, l.cfg.AllowDuplicates); len(problems) > 0 {
+			errs = append(errs, problems...)
+			continue
+		}
+		cmd.StrictEnv = cmd.StrictEnv || l.cfg.StrictEnv
+		if !applyPlatform(&cmd, currentOS) {
+			delete(l.cfg.Commands, name)
+			unavailable = append(unavailable, name)
+			continue
+		}
+		if (cmd.Shell != "" || cmd.Steps != nil) && cmd.Interpreter == nil && validInterpreter(l.cfg.Interpreter) {
+			cmd.Interpreter = l.cfg.Interpreter
+		}
+		if problems := validateCommand(name, &cmd, filepath.Dir(l.path)); len(problems) > 0 {
+			errs = append(errs, problems...)
+			continue
+		}
+		if problems := validateAliases(name, &cmd); len(problems) > 0 {
+			errs = append(errs, problems...)
+			continue
+		}
+		l.cfg.Commands[name] = cmd
+	}
+	if l.cfg.Desktop != nil {
+		errs = append(errs, validateDesktop(l.cfg.Desktop, filepath.Dir(l.path))...)
+	}
+
+	for _, e := range errs {
+		e.File = l.path
+		if e.Line == 0 {
+			e.Line, e.Column = l.positions.lookup(fieldPath(e)...)
+		}
+	}
+	return errs, unavailable
+}
+
+// duplicateErrors reports a command that is defined more than once in the file.
+// encoding/json keeps the last definition; with allow it is accepted with a warning.
+func duplicateErrors(positions *positionIndex, name string, allow bool) ValidationErrors {
+	definitions := positions.definitions("commands", name)
+	if len(definitions) == 0 {
+		return nil
+	}
+
+	first := definitions[0]
+	if allow {
+		last := definitions[len(definitions)-1]
+		logger.Warn("Command '%s' is defined %d times; using the definition at line %d, column %d",
+			name, len(definitions), last.line, last.column)
+		return nil
+	}
+
+	var errs ValidationErrors
+	for _, pos := range definitions[1:] {
+		errs = append(errs, &ValidationError{
+			Command: name,
+			Line:    pos.line,
+			Column:  pos.column,
+			Message: fmt.Sprintf("duplicate command, first defined at line %d, column %d", first.line, first.column),
+		})
+	}
+	return errs
+}
+
+// validateCommand checks a single command, normalizing its fields in place.
+// Relative paths are resolved against baseDir, the directory of the file defining it.
+// It returns every problem found; positions are filled in by the caller.
+func validateCommand(name string, cmd *Command, baseDir string) ValidationErrors {
+	var errs ValidationErrors
+
+	// Validate command name
+	if name == "" {
+		errs = append(errs, &ValidationError{Message: "command name cannot be empty"})
+	}
+
+	// Validate required fields
+	if cmd.Path == "" && cmd.Shell == "" && cmd.Steps == nil {
+		errs = append(errs, &ValidationError{Command: name, Field: "path", Message: "must have a non-empty path"})
+	}
+	errs = append(errs, validateShell(name, cmd)...)
+	errs = append(errs, validateSteps(name, cmd)...)
+	errs = append(errs, validateOutput(name, cmd)...)
+
+	// Args can be nil or empty, but if present must be a valid slice
+	if cmd.Args == nil {
+		cmd.Args = []string{}
+	}
+
+	errs = append(errs, validateMetadata(name, cmd, baseDir)...)
+	errs = append(errs, validateSingleInstance(name, cmd)...)
+	return append(errs, validateEnvironment(name, cmd, baseDir)...)
+}
+
+// validateMetadata checks the tags of a command, dropping a leading "#", and resolves
+// its icon to an absolute path
+func validateMetadata(name string, cmd *Command, baseDir string) ValidationErrors {
+	var errs ValidationErrors
+	for i, tag := range cmd.Tags {
+		tag = strings.TrimPrefix(tag, "#")
+		if tag == "" || strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+			errs = append(errs, &ValidationError{
+				Command: name,
+				Field:   "tags",
+				Message: fmt.Sprintf("invalid tag %q: must be non-empty and contain no whitespace", cmd.Tags[i]),
+				path:    []string{"commands", name, "tags", strconv.Itoa(i)},
+			})
+			continue
+		}
+		cmd.Tags[i] = tag
+	}
+
+	if cmd.Icon == "" {
+		return errs
+	}
+	icon, err := resolvePath(cmd.Icon, baseDir)
+	if err != nil {
+		return append(errs, &ValidationError{Command: name, Field: "icon", Message: fmt.Sprintf("invalid icon: %v", err)})
+	}
+	if _, err := os.Stat(icon); err != nil {
+		// A missing icon only affects how the command is shown
+		logger.Warn("Icon of command '%s' is not accessible: %v", name, err)
+	}
+	cmd.Icon = icon
+	return errs
+}
+
+// validateEnvironment checks the env variable names of a command and resolves its
+// working directory to an absolute path
+func validateEnvironment(name string, cmd *Command, baseDir string) ValidationErrors {
+	var errs ValidationErrors
+	for key := range cmd.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			errs = append(errs, &ValidationError{
+				Command: name,
+				Field:   "env",
+				Message: fmt.Sprintf("invalid environment variable name %q", key),
+				path:    []string{"commands", name, "env", key},
+			})
+		}
+	}
+
+	// A cwd starting with an environment variable may or may not be absolute once the
+	// executor expands it, so it is left alone
+	if cmd.Cwd == "" || strings.HasPrefix(cmd.Cwd, "$") || strings.HasPrefix(cmd.Cwd, "%") {
+		return errs
+	}
+
+	dir, err := resolvePath(cmd.Cwd, baseDir)
+	if err != nil {
+		return append(errs, &ValidationError{Command: name, Field: "cwd", Message: fmt.Sprintf("invalid cwd: %v", err)})
+	}
+	if strings.ContainsAny(dir, "$%") {
+		// Only known at launch
+		cmd.Cwd = dir
+		return errs
+	}
+
+	info, err := os.Stat(dir)
+	switch {
+	case err == nil && !info.IsDir():
+		return append(errs, &ValidationError{Command: name, Field: "cwd", Message: fmt.Sprintf("'%s' is not a directory", dir)})
+	case err != nil:
+		// The directory may appear later (e.g. a mounted drive), so only warn here
+		logger.Warn("Working directory of command '%s' is not accessible: %v", name, err)
+	}
+
+	cmd.Cwd = dir
+	return errs
+}
+
+// fieldPath returns the location of a problem in the JSON document
+func fieldPath(e *ValidationError) []string {
+	if e.path != nil {
+		return e.path
+	}
+	path := []string{"commands", e.Command}
+	if e.Field != "" {
+		path = append(path, e.Field)
+	}
+	return path
+}
+
+// resolvePath expands a leading ~ to the home directory and makes relative paths
+// absolute by joining them to baseDir
+func resolvePath(path, baseDir string) (string, error) {
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~\\") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("cannot expand '~': %w", err)
+		}
+		path = filepath.Join(home, path[1:])
+	}
+
+	if !filepath.IsAbs(path) {
+		absBase, err := filepath.Abs(baseDir)
+		if err != nil {
+			return "", err
+		}
+		path = filepath.Join(absBase, path)
+	}
+
+	return filepath.Clean(path), nil
+}
+
+// GetCommand retrieves a command by name or alias with O(1) lookup.
+// Surrounding whitespace is ignored, and so is case if the configuration asks for it.
 func (c *ConfigManager) GetCommand(name string) (Command, bool) {
-	cmd, exists := c.commands[name]
+	c.mu.RLock()
+	canonical, exists := c.names.resolve(name)
+	cmd := c.commands[canonical]
+	c.mu.RUnlock()
 	if !exists {
 		logger.Warn("Command lookup failed: '%s' not found in configuration", name)
 	}
 	return cmd, exists
 }
+
+// CommandName returns the name of the command that name refers to, resolving
+// aliases, case and surrounding whitespace as GetCommand does
+func (c *ConfigManager) CommandName(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.names.resolve(name)
+}
+
+// Origin returns the configuration file that defined a command
+func (c *ConfigManager) Origin(name string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	origin, exists := c.origins[name]
+	return origin, exists
+}
+
+// Warnings returns the problems the last successful Load tolerated, such as unknown fields in
+// lenient files, sorted by position
+func (c *ConfigManager) Warnings() ValidationErrors {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append(ValidationErrors(nil), c.warnings...)
+}
+
+// PathCommands reports whether the configuration enables offering the executables on
+// $PATH as commands (see pathindex)
+func (c *ConfigManager) PathCommands() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.pathCmds
+}
+
+// CommandNames returns the names of all loaded commands that are not hidden, in sorted order
+func (c *ConfigManager) CommandNames() []string {
+	return c.commandNames(false)
+}
+
+// AllCommandNames returns the names of all loaded commands including hidden ones, in sorted order
+func (c *ConfigManager) AllCommandNames() []string {
+	return c.commandNames(true)
+}
+
+// commandNames returns the sorted command names, leaving out hidden commands unless
+// includeHidden is set
+func (c *ConfigManager) commandNames(includeHidden bool) []string {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.commands))
+	for name, cmd := range c.commands {
+		if includeHidden || !cmd.Hidden {
+			names = append(names, name)
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Watch starts watching the configuration file and reloads it whenever it changes.
+// onReload, if not nil, is called from the watcher goroutine with the result of
+// every reload attempt (nil on success). A failed reload keeps the old commands.
+func (c *ConfigManager) Watch(onReload func(error)) error {
+	if c.watcher != nil {
+		return fmt.Errorf("config file is already being watched")
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.Error("Failed to create configuration watcher: %v", err)
+		return fmt.Errorf("failed to create config watcher: %w", err)
+	}
+
+	// Watch the directory rather than the file itself so that editors which save
+	// by renaming a temporary file over the original are picked up as well
+	dir := filepath.Dir(c.configPath)
+	if err := watcher.Add(dir); err != nil {
+		watcher.Close()
+		logger.Error("Failed to watch configuration directory '%s': %v", dir, err)
+		return fmt.Errorf("failed to watch config directory: %w", err)
+	}
+	c.watchIncludes(watcher)
+
+	c.watcher = watcher
+	go c.watchLoop(watcher, onReload)
+
+	logger.Info("Watching configuration file for changes: %s", c.configPath)
+	return nil
+}
+
+// Close stops watching the configuration file
+func (c *ConfigManager) Close() error {
+	if c.watcher == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mu.Unlock()
+
+	err := c.watcher.Close()
+	c.watcher = nil
+	logger.Info("Stopped watching configuration file")
+	return err
+}
+
+// watchLoop handles file system events until the watcher is closed
+func (c *ConfigManager) watchLoop(watcher *fsnotify.Watcher, onReload func(error)) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !c.affectsConfig(event) {
+				continue
+			}
+			c.scheduleReload(watcher, onReload)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			logger.Warn("Configuration watcher error: %v", err)
+		}
+	}
+}
+
+// affectsConfig reports whether a file system event concerns the main configuration
+// file, one of the files it includes, or a file appearing in or leaving an include pattern
+func (c *ConfigManager) affectsConfig(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	if name == filepath.Clean(c.configPath) {
+		return event.Has(fsnotify.Write | fsnotify.Create)
+	}
+	if absName, err := filepath.Abs(name); err == nil {
+		name = absName
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if event.Has(fsnotify.Write | fsnotify.Create) {
+		for _, source := range c.sources {
+			if name == source {
+				return true
+			}
+		}
+	}
+	for _, pattern := range c.patterns {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// watchIncludes adds the directories of included files and include patterns to the watcher.
+// Directories that do not exist (yet) are skipped.
+func (c *ConfigManager) watchIncludes(watcher *fsnotify.Watcher) {
+	c.mu.RLock()
+	dirs := make(map[string]bool)
+	for _, path := range append(c.sources[:len(c.sources):len(c.sources)], c.patterns...) {
+		dirs[filepath.Dir(path)] = true
+	}
+	c.mu.RUnlock()
+
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil && !errors.Is(err, fsnotify.ErrClosed) {
+			logger.Warn("Cannot watch included configuration directory '%s': %v", dir, err)
+		}
+	}
+}
+
+// scheduleReload (re)starts the debounce timer for a reload
+func (c *ConfigManager) scheduleReload(watcher *fsnotify.Watcher, onReload func(error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.timer = time.AfterFunc(reloadDebounce, func() {
+		logger.Info("Configuration file changed, reloading")
+		err := c.Load()
+		if err != nil {
+			logger.Error("Configuration reload failed, keeping previous commands: %v", err)
+		}
+		// The reloaded files may include files from new directories
+		c.watchIncludes(watcher)
+		if onReload != nil {
+			onReload(err)
+		}
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/leanovate/gopter"
 	"github.com/leanovate/gopter/gen"
@@ -254,6 +256,368 @@ func TestLoadEmptyConfiguration(t *testing.T) {
 	}
 }
 
+// TestWatchReloadsChangedConfiguration tests that edits to the config file are picked up
+func TestWatchReloadsChangedConfiguration(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.json")
+	writeConfig(t, configFile, `{"commands": {"old": {"path": "/bin/old", "args": []}}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	reloaded := make(chan error, 10)
+	if err := cm.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("Failed to watch configuration: %v", err)
+	}
+	defer cm.Close()
+
+	writeConfig(t, configFile, `{"commands": {"new": {"path": "/bin/new", "args": []}}}`)
+
+	if err := waitForReload(t, reloaded); err != nil {
+		t.Fatalf("Expected successful reload, got: %v", err)
+	}
+	if _, exists := cm.GetCommand("new"); !exists {
+		t.Error("Command 'new' should be available after reload")
+	}
+	if _, exists := cm.GetCommand("old"); exists {
+		t.Error("Command 'old' should be gone after reload")
+	}
+}
+
+// TestWatchKeepsCommandsOnInvalidReload tests that a broken edit does not drop the loaded commands
+func TestWatchKeepsCommandsOnInvalidReload(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "config.json")
+	writeConfig(t, configFile, `{"commands": {"editor": {"path": "/bin/editor", "args": []}}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	reloaded := make(chan error, 10)
+	if err := cm.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("Failed to watch configuration: %v", err)
+	}
+	defer cm.Close()
+
+	writeConfig(t, configFile, `{"commands": {"editor": {"path": ""}}`)
+
+	if err := waitForReload(t, reloaded); err == nil {
+		t.Fatal("Expected reload of invalid configuration to fail")
+	}
+	cmd, exists := cm.GetCommand("editor")
+	if !exists || cmd.Path != "/bin/editor" {
+		t.Errorf("Previous command should stay active after failed reload, got %v (exists=%v)", cmd, exists)
+	}
+}
+
+// TestFailedLoadKeepsState tests that a failed load leaves the watched files and
+// warnings of the previous load in place along with its commands
+func TestFailedLoadKeepsState(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig(t, filepath.Join(dir, "a.json"), `{"commands": {"a": {"path": "/bin/a"}}}`)
+	writeConfig(t, configFile, `{"lenient": true, "include": ["a.json"], "commands": {"editor": {"path": "/bin/editor", "colour": "red"}}}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	sources, patterns, warnings := cm.sources, cm.patterns, cm.Warnings()
+	if len(sources) != 2 || len(warnings) != 1 {
+		t.Fatalf("Expected two sources and one warning, got %v and %v", sources, warnings)
+	}
+
+	writeConfig(t, configFile, `{"lenient": true, "include": ["b/*.json"], "commands": {"editor": {"path": "", "size": 1}}}`)
+	if err := cm.Load(); err == nil {
+		t.Fatal("Expected the load to fail")
+	}
+	if !reflect.DeepEqual(cm.sources, sources) || !reflect.DeepEqual(cm.patterns, patterns) || !reflect.DeepEqual(cm.Warnings(), warnings) {
+		t.Errorf("Expected the previous state to be kept, got sources %v, patterns %v, warnings %v", cm.sources, cm.patterns, cm.Warnings())
+	}
+	if _, exists := cm.GetCommand("a"); !exists {
+		t.Error("Expected the previous commands to be kept")
+	}
+}
+
+// TestLoadResolvesWorkingDirectory tests resolution of relative and ~ working directories.
+// Directories referring to environment variables are left for the executor.
+func TestLoadResolvesWorkingDirectory(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "project"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory available: %v", err)
+	}
+
+	configFile := filepath.Join(tmpDir, "config.json")
+	writeConfig(t, configFile, `{"commands": {
+		"relative": {"path": "/bin/app", "args": [], "cwd": "project"},
+		"home": {"path": "/bin/app", "args": [], "cwd": "~"},
+		"env": {"path": "/bin/app", "args": [], "env": {"GOFLAGS": "-mod=vendor"}, "env_clear": true},
+		"variable": {"path": "/bin/app", "args": [], "cwd": "${PROJECTS}/app"},
+		"nested": {"path": "/bin/app", "args": [], "cwd": "project/$BRANCH", "strict_env": true}
+	}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	absDir, _ := filepath.Abs(filepath.Join(tmpDir, "project"))
+	if cmd, _ := cm.GetCommand("relative"); cmd.Cwd != absDir {
+		t.Errorf("Expected relative cwd to resolve to %s, got %s", absDir, cmd.Cwd)
+	}
+	if cmd, _ := cm.GetCommand("home"); cmd.Cwd != filepath.Clean(home) {
+		t.Errorf("Expected ~ to resolve to %s, got %s", home, cmd.Cwd)
+	}
+	cmd, _ := cm.GetCommand("env")
+	if cmd.Env["GOFLAGS"] != "-mod=vendor" || !cmd.EnvClear {
+		t.Errorf("Expected env and env_clear to be loaded, got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("variable"); cmd.Cwd != "${PROJECTS}/app" {
+		t.Errorf("Expected a cwd starting with a variable to be kept, got %s", cmd.Cwd)
+	}
+	if cmd, _ := cm.GetCommand("nested"); cmd.Cwd != filepath.Join(absDir, "$BRANCH") || !cmd.StrictEnv {
+		t.Errorf("Expected relative cwd with a variable to resolve without checking, got %+v", cmd)
+	}
+}
+
+// TestLoadStrictEnvForFile tests that a root strict_env applies to the commands of its file only
+func TestLoadStrictEnvForFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainFile := filepath.Join(tmpDir, "config.json")
+	writeConfig(t, mainFile, `{"strict_env": true, "include": ["lenient.json"], "commands": {
+		"strict": {"path": "$TOOLS/strict", "args": []}
+	}}`)
+	writeConfig(t, filepath.Join(tmpDir, "lenient.json"), `{"commands": {
+		"lenient": {"path": "$TOOLS/lenient", "args": []}
+	}}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("strict"); !cmd.StrictEnv {
+		t.Error("Expected strict_env to apply to commands of the same file")
+	}
+	if cmd, _ := cm.GetCommand("lenient"); cmd.StrictEnv {
+		t.Error("Expected strict_env not to apply to included files")
+	}
+}
+
+// TestLoadRejectsInvalidEnvironment tests validation of cwd and env fields
+func TestLoadRejectsInvalidEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	notADir := filepath.Join(tmpDir, "file.txt")
+	writeConfig(t, notADir, "")
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty env name", `{"commands": {"bad": {"path": "/bin/app", "env": {"": "x"}}}}`},
+		{"env name with equals", `{"commands": {"bad": {"path": "/bin/app", "env": {"A=B": "x"}}}}`},
+		{"cwd is a file", `{"commands": {"bad": {"path": "/bin/app", "cwd": "file.txt"}}}`},
+		{"env is not an object", `{"commands": {"bad": {"path": "/bin/app", "env": ["A=B"]}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(tmpDir, "config.json")
+			writeConfig(t, configFile, tt.content)
+
+			cm, err := NewConfigManager(configFile)
+			if err != nil {
+				t.Fatalf("Failed to create ConfigManager: %v", err)
+			}
+			err = cm.Load()
+			if err == nil {
+				t.Fatal("Expected validation error, got nil")
+			}
+			if !contains(err.Error(), "bad") && !contains(err.Error(), "env") {
+				t.Errorf("Error should name the command or field: %v", err)
+			}
+		})
+	}
+}
+
+// TestLoadReportsEveryInvalidCommand tests that validation does not stop at the first error
+func TestLoadReportsEveryInvalidCommand(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"commands": {
+		"first": {"path": ""},
+		"good": {"path": "/bin/app"},
+		"second": {"path": "/bin/app", "env": {"": "x"}}
+	}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	err = cm.Load()
+	if err == nil {
+		t.Fatal("Expected validation error, got nil")
+	}
+	for _, name := range []string{"'first'", "'second'"} {
+		if !contains(err.Error(), name) {
+			t.Errorf("Error should mention command %s: %v", name, err)
+		}
+	}
+	if contains(err.Error(), "'good'") {
+		t.Errorf("Error should not mention valid command: %v", err)
+	}
+}
+
+// TestLoadRejectsDuplicateCommands tests that every repeated definition of a command is reported
+func TestLoadRejectsDuplicateCommands(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"commands": {
+  "chrome": {"path": "/bin/first"},
+  "vscode": {"path": "/bin/code"},
+  "chrome": {"path": "/bin/second"},
+  "chrome": {"path": "/bin/third"}
+}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	errs := AsValidationErrors(cm.Load())
+	if len(errs) != 2 {
+		t.Fatalf("Expected 2 errors, got: %v", errs)
+	}
+	for i, line := range []int{4, 5} {
+		if errs[i].Command != "chrome" || errs[i].Line != line || errs[i].Column != 3 {
+			t.Errorf("Expected duplicate of 'chrome' at line %d, got: %v", line, errs[i])
+		}
+		if !contains(errs[i].Message, "first defined at line 2, column 3") {
+			t.Errorf("Error should point at the first definition: %v", errs[i])
+		}
+	}
+	if _, exists := cm.GetCommand("vscode"); exists {
+		t.Error("No commands should be loaded from an invalid configuration")
+	}
+}
+
+// TestLoadAllowDuplicates tests that the override keeps the last definition
+func TestLoadAllowDuplicates(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"allow_duplicates": true, "commands": {
+  "chrome": {"path": "/bin/first"},
+  "chrome": {"path": "/bin/second"}
+}}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Expected duplicates to be allowed, got: %v", err)
+	}
+	cmd, exists := cm.GetCommand("chrome")
+	if !exists || cmd.Path != "/bin/second" {
+		t.Errorf("Expected the last definition to win, got: %+v", cmd)
+	}
+}
+
+// TestLoadPathCommands tests that path_commands in any file enables PATH commands
+func TestLoadPathCommands(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig(t, configFile, `{"commands": {}}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cm.PathCommands() {
+		t.Error("Expected PATH commands to be off by default")
+	}
+
+	writeConfig(t, filepath.Join(dir, "extra.toml"), "path_commands = true\n")
+	writeConfig(t, configFile, `{"include": ["extra.toml"], "commands": {}}`)
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to reload configuration: %v", err)
+	}
+	if !cm.PathCommands() {
+		t.Error("Expected path_commands in an included file to enable PATH commands")
+	}
+}
+
+// TestLoadCommandMetadata tests description, tags, icon and hidden commands
+func TestLoadCommandMetadata(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig(t, configFile, `{"commands": {
+  "vscode": {"path": "/bin/code", "description": "Editor", "tags": ["#dev", "editor"], "icon": "icons/code.png"},
+  "secret": {"path": "/bin/secret", "hidden": true}
+}}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	cmd, _ := cm.GetCommand("vscode")
+	if cmd.Description != "Editor" || len(cmd.Tags) != 2 || cmd.Tags[0] != "dev" || cmd.Tags[1] != "editor" {
+		t.Errorf("Expected description and tags without '#', got %+v", cmd)
+	}
+	if cmd.Icon != filepath.Join(dir, "icons", "code.png") {
+		t.Errorf("Expected the icon relative to the configuration file, got %s", cmd.Icon)
+	}
+
+	if names := cm.CommandNames(); len(names) != 1 || names[0] != "vscode" {
+		t.Errorf("Expected the hidden command to be left out, got %v", names)
+	}
+	if names := cm.AllCommandNames(); len(names) != 2 {
+		t.Errorf("Expected all commands including hidden ones, got %v", names)
+	}
+	if _, exists := cm.GetCommand("secret"); !exists {
+		t.Error("Expected the hidden command to be found by its name")
+	}
+
+	writeConfig(t, configFile, `{"commands": {"vscode": {"path": "/bin/code", "tags": ["#", "two words"]}}}`)
+	errs := AsValidationErrors(cm.Load())
+	if len(errs) != 2 || errs[0].Field != "tags" || errs[1].Field != "tags" {
+		t.Errorf("Expected both tags to be rejected, got: %v", errs)
+	}
+}
+
+// writeConfig writes a configuration file for tests
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+}
+
+// waitForReload waits for the watcher to report a reload attempt
+func waitForReload(t *testing.T, reloaded <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-reloaded:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for configuration reload")
+		return nil
+	}
+}
+
 // **Feature: app-launcher, Property 8: Invalid configurations fail gracefully**
 // **Validates: Requirements 3.3**
 // For any missing or malformed configuration file, the launcher should display
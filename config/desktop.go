@@ -0,0 +1,152 @@
+package config
+
+import (
+	"app-launcher/desktop"
+	"app-launcher/logger"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DesktopImport configures importing installed applications from freedesktop.org
+// .desktop files (Linux and other XDG desktops).
+//
+// Example JSON:
+//
+//	{
+//	  "desktop": {"enabled": true},
+//	  "commands": {}
+//	}
+//
+// Every launchable entry becomes a command named after its desktop file ID, e.g.
+// "firefox" for firefox.desktop. Hand-written commands always override imported ones.
+//
+// Fields:
+//   - Enabled: Whether to import applications at all.
+//   - Dirs: Applications directories to scan, earlier ones shadowing later ones.
+//     Relative paths are resolved against the configuration file. Defaults to
+//     $XDG_DATA_HOME/applications and the applications directory of every $XDG_DATA_DIRS entry.
+//   - Terminal: Program and arguments that run entries with Terminal=true, followed by
+//     the entry's own command line. Defaults to "$TERMINAL -e", or "x-terminal-emulator -e".
+type DesktopImport struct {
+	Enabled  bool     `json:"enabled" toml:"enabled" yaml:"enabled"`                                  // Import .desktop entries
+	Dirs     []string `json:"dirs,omitempty" toml:"dirs,omitempty" yaml:"dirs,omitempty"`             // Applications directories to scan
+	Terminal []string `json:"terminal,omitempty" toml:"terminal,omitempty" yaml:"terminal,omitempty"` // Terminal command prefix
+}
+
+// validateDesktop resolves the applications directories of a file's desktop section
+// against baseDir, in place
+func validateDesktop(settings *DesktopImport, baseDir string) ValidationErrors {
+	var errs ValidationErrors
+	for i, dir := range settings.Dirs {
+		resolved, err := resolvePath(dir, baseDir)
+		if err != nil {
+			errs = append(errs, &ValidationError{
+				Field:   "desktop",
+				Message: "invalid dir: " + err.Error(),
+				path:    []string{"desktop", "dirs", strconv.Itoa(i)},
+			})
+			continue
+		}
+		settings.Dirs[i] = resolved
+	}
+	return errs
+}
+
+// desktopSettings returns the desktop section of the file with the highest precedence
+// that has one, or nil if importing is not configured
+func desktopSettings(layers []layer) *DesktopImport {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if settings := layers[i].cfg.Desktop; settings != nil {
+			return settings
+		}
+	}
+	return nil
+}
+
+// importDesktop converts the launchable .desktop entries to commands.
+// Entries that cannot be parsed or converted are logged and skipped rather than failing
+// the whole configuration. It returns the commands, the file defining each of them and
+// glob patterns matching the scanned files, so that the watcher picks up new applications.
+func importDesktop(settings *DesktopImport) (map[string]Command, map[string]string, []string) {
+	dirs := settings.Dirs
+	if len(dirs) == 0 {
+		dirs = desktop.Dirs()
+	}
+	terminal := settings.Terminal
+	if len(terminal) == 0 {
+		terminal = defaultTerminal()
+	}
+
+	entries, errs := desktop.Scan(dirs, desktop.Locale())
+	for _, err := range errs {
+		logger.Warn("Skipping desktop entry: %v", err)
+	}
+
+	commands := make(map[string]Command, len(entries))
+	origins := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		cmd := desktopCommand(entry, terminal)
+		if problems := validateCommand(entry.ID, &cmd, filepath.Dir(entry.File)); len(problems) > 0 {
+			logger.Warn("Skipping desktop entry '%s': %v", entry.File, problems)
+			continue
+		}
+		// The name shown in menus launches the application too. Unlike hand-written
+		// aliases it may contain spaces, so it is added once the command is validated.
+		if entry.Name != "" && entry.Name != entry.ID {
+			cmd.Aliases = []string{entry.Name}
+		}
+		commands[entry.ID] = cmd
+		origins[entry.ID] = entry.File
+	}
+
+	patterns := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		patterns = append(patterns, filepath.Join(dir, "*.desktop"))
+	}
+
+	logger.Info("Imported %d applications from %d desktop directories", len(commands), len(dirs))
+	return commands, origins, patterns
+}
+
+// desktopCommand converts a .desktop entry to a command
+func desktopCommand(entry *desktop.Entry, terminal []string) Command {
+	argv := entry.Exec
+	if entry.Terminal {
+		argv = append(append([]string{}, terminal...), argv...)
+	}
+
+	// The command line comes from the entry literally, so braces must not be taken
+	// for placeholders, nor $ and % signs for environment variables
+	args := make([]string, 0, len(argv)-1)
+	for _, arg := range argv[1:] {
+		args = append(args, escapeLiteral(arg))
+	}
+	cmd := Command{
+		Path:        escapeLiteral(argv[0]),
+		Args:        args,
+		AllowArgs:   entry.AcceptsArgs,
+		Cwd:         entry.Path,
+		Description: entry.Comment,
+	}
+	// Icon is usually a name from the icon theme, which cannot be shown; only files are used
+	if filepath.IsAbs(entry.Icon) {
+		cmd.Icon = entry.Icon
+	}
+	return cmd
+}
+
+// defaultTerminal returns the terminal used for entries with Terminal=true when none is configured
+func defaultTerminal() []string {
+	if terminal := os.Getenv("TERMINAL"); terminal != "" {
+		return []string{terminal, "-e"}
+	}
+	return []string{"x-terminal-emulator", "-e"}
+}
+
+// escapeLiteral doubles braces and $ and % signs so that the executor expands them
+// to themselves
+func escapeLiteral(s string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}", "$", "$$", "%", "%%").Replace(s)
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLoadImportsDesktopEntries tests that .desktop entries become commands and
+// hand-written commands override them
+func TestLoadImportsDesktopEntries(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Join("..", "testdata", "desktop"))
+	if err != nil {
+		t.Fatalf("Failed to resolve fixtures: %v", err)
+	}
+	t.Setenv("LC_ALL", "C")
+
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{
+  "desktop": {
+    "enabled": true,
+    "dirs": [`+strings.Join([]string{
+		`"` + filepath.ToSlash(filepath.Join(fixtures, "home", "applications")) + `"`,
+		`"` + filepath.ToSlash(filepath.Join(fixtures, "system", "applications")) + `"`,
+	}, ", ")+`],
+    "terminal": ["xterm", "-e"]
+  },
+  "commands": {"firefox": {"path": "/usr/bin/firefox", "args": ["--private-window"]}}
+}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	expected := []string{"editor", "firefox", "htop", "kde-konsole"}
+	if names := cm.CommandNames(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected commands %v, got %v", expected, names)
+	}
+
+	if cmd, _ := cm.GetCommand("firefox"); cmd.Path != "/usr/bin/firefox" {
+		t.Errorf("Expected the hand-written command to override the desktop entry, got %+v", cmd)
+	}
+	if origin, _ := cm.Origin("firefox"); origin != mainFile {
+		t.Errorf("Expected firefox to come from %s, got %s", mainFile, origin)
+	}
+
+	editor, _ := cm.GetCommand("editor")
+	expectedEditor := Command{
+		Path:        "/opt/My Editor/editor",
+		Args:        []string{"--name", "Text Editor", "--icon", "accessories-text-editor", "--format={{json}}", "--stamp=%%Y"},
+		AllowArgs:   true,
+		Cwd:         filepath.Clean("/"),
+		Aliases:     []string{"Text Editor"},
+		Description: "Edit text files",
+	}
+	if !reflect.DeepEqual(editor, expectedEditor) {
+		t.Errorf("Unexpected editor command:\n got: %+v\nwant: %+v", editor, expectedEditor)
+	}
+	if origin, _ := cm.Origin("editor"); origin != filepath.Join(fixtures, "system", "applications", "editor.desktop") {
+		t.Errorf("Expected editor to come from its .desktop file, got %s", origin)
+	}
+
+	if htop, _ := cm.GetCommand("htop"); htop.Path != "xterm" || !reflect.DeepEqual(htop.Args, []string{"-e", "htop"}) {
+		t.Errorf("Expected htop to run in the configured terminal, got %+v", htop)
+	}
+	if name, ok := cm.CommandName("Konsole"); !ok || name != "kde-konsole" {
+		t.Errorf("Expected the entry's Name to launch it, got %q, %v", name, ok)
+	}
+}
+
+// TestLoadDesktopEntryNames tests that imported applications can be launched by the
+// name shown in menus, in the user's language, unless another command has that name
+func TestLoadDesktopEntryNames(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Join("..", "testdata", "desktop", "system", "applications"))
+	if err != nil {
+		t.Fatalf("Failed to resolve fixtures: %v", err)
+	}
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+
+	mainFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, mainFile, `{
+  "desktop": {"enabled": true, "dirs": ["`+filepath.ToSlash(fixtures)+`"]},
+  "commands": {"term": {"path": "/usr/bin/xterm", "aliases": ["Konsole"]}}
+}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cmd, ok := cm.GetCommand("Texteditor"); !ok || cmd.Path != "/opt/My Editor/editor" {
+		t.Errorf("Expected the German name to launch the editor, got %+v, %v", cmd, ok)
+	}
+	if _, ok := cm.GetCommand("Text Editor"); ok {
+		t.Error("Expected only the localized name to be registered")
+	}
+
+	if name, _ := cm.CommandName("Konsole"); name != "term" {
+		t.Errorf("Expected the configured alias to keep its name, got %q", name)
+	}
+	if cmd, _ := cm.GetCommand("kde-konsole"); len(cmd.Aliases) != 0 {
+		t.Errorf("Expected the taken name to be dropped, got %v", cmd.Aliases)
+	}
+}
+
+// TestLoadResolvesReferencesToDesktopEntries tests that macro steps and activate
+// commands can name imported applications
+func TestLoadResolvesReferencesToDesktopEntries(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Join("..", "testdata", "desktop", "system", "applications"))
+	if err != nil {
+		t.Fatalf("Failed to resolve fixtures: %v", err)
+	}
+
+	mainFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, mainFile, `{
+  "desktop": {"enabled": true, "dirs": ["`+filepath.ToSlash(fixtures)+`"]},
+  "commands": {
+    "morning": {"steps": [{"command": "editor"}]},
+    "notes": {"path": "/usr/bin/notes", "single_instance": "activate", "activate": "editor"}
+  }
+}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Expected references to desktop entries to load: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("morning"); len(cmd.Steps) != 1 || cmd.Steps[0].Command != "editor" {
+		t.Errorf("Expected the step to run the desktop entry, got %+v", cmd.Steps)
+	}
+	if cmd, _ := cm.GetCommand("notes"); cmd.Activate != "editor" {
+		t.Errorf("Expected the desktop entry to be activated, got %+v", cmd)
+	}
+
+	writeConfig(t, mainFile, `{"commands": {"morning": {"steps": [{"command": "editor"}]}}}`)
+	if err := cm.Load(); err == nil || !strings.Contains(err.Error(), "command 'editor' not found") {
+		t.Errorf("Expected the step to be unknown without the import, got: %v", err)
+	}
+}
+
+// TestLoadDesktopDisabled tests that nothing is imported unless enabled
+func TestLoadDesktopDisabled(t *testing.T) {
+	fixtures, err := filepath.Abs(filepath.Join("..", "testdata", "desktop", "system", "applications"))
+	if err != nil {
+		t.Fatalf("Failed to resolve fixtures: %v", err)
+	}
+
+	mainFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, mainFile, "desktop:\n  enabled: false\n  dirs: ['"+fixtures+"']\n")
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if names := cm.CommandNames(); len(names) != 0 {
+		t.Errorf("Expected no commands, got %v", names)
+	}
+}
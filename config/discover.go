@@ -0,0 +1,169 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// PathEnv names the environment variable that overrides the configuration file location
+const PathEnv = "LAUNCHER_CONFIG"
+
+// appDir is the directory created for the launcher inside each configuration directory
+const appDir = "launcher"
+
+// configNames are the file names looked for in each configuration directory, in order
+var configNames = []string{"config.json", "config.toml", "config.yaml", "config.yml"}
+
+// SearchDirs returns the directories searched for a configuration file, in order:
+// $XDG_CONFIG_HOME (default ~/.config), every entry of $XDG_CONFIG_DIRS (default
+// /etc/xdg), then %APPDATA% on Windows. On Windows the XDG variables are only used
+// when set explicitly.
+func SearchDirs() []string {
+	return searchDirs(runtime.GOOS, os.Getenv, os.UserHomeDir)
+}
+
+// searchDirs implements SearchDirs for the given platform and environment
+func searchDirs(goos string, getenv func(string) string, home func() (string, error)) []string {
+	var dirs []string
+	add := func(dir string) {
+		// Relative entries are invalid per the XDG specification and would
+		// make the result depend on the working directory
+		if dir != "" && filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Join(dir, appDir))
+		}
+	}
+
+	configHome := getenv("XDG_CONFIG_HOME")
+	if configHome == "" && goos != "windows" {
+		if h, err := home(); err == nil {
+			configHome = filepath.Join(h, ".config")
+		}
+	}
+	add(configHome)
+
+	configDirs := getenv("XDG_CONFIG_DIRS")
+	if configDirs == "" && goos != "windows" {
+		configDirs = "/etc/xdg"
+	}
+	for _, dir := range filepath.SplitList(configDirs) {
+		add(dir)
+	}
+
+	if goos == "windows" {
+		add(getenv("APPDATA"))
+	}
+	return dirs
+}
+
+// DefaultPath returns the configuration file to use when none is given on the command line.
+//
+// $LAUNCHER_CONFIG is used as is when set. Otherwise the first existing config.json,
+// config.toml, config.yaml or config.yml in SearchDirs is used. If there is none, the
+// result is config.json in the first search directory, which is where Init writes.
+func DefaultPath() string {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path
+	}
+
+	dirs := SearchDirs()
+	for _, dir := range dirs {
+		for _, name := range configNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+	}
+
+	if len(dirs) == 0 {
+		// No home directory or configuration directories at all
+		return configNames[0]
+	}
+	return filepath.Join(dirs[0], configNames[0])
+}
+
+// Init writes a starter configuration in the given format. With an explicit path
+// (or $LAUNCHER_CONFIG) only that file is tried; otherwise the starter is written to
+// the first search directory that can be created and written to. An existing
+// configuration is never overwritten unless force is set. Returns the path written.
+func Init(path string, format Format, force bool) (string, error) {
+	data, err := Encode(StarterConfig(runtime.GOOS), format)
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		path = os.Getenv(PathEnv)
+	}
+	if path != "" {
+		return path, writeStarter(path, data, force)
+	}
+
+	if existing := DefaultPath(); !force && fileExists(existing) {
+		return "", fmt.Errorf("configuration already exists at '%s'", existing)
+	}
+
+	var errs []error
+	for _, dir := range SearchDirs() {
+		path := filepath.Join(dir, "config."+string(format))
+		err := writeStarter(path, data, force)
+		if err == nil {
+			return path, nil
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return "", fmt.Errorf("no configuration directory found (set %s or XDG_CONFIG_HOME)", PathEnv)
+	}
+	return "", fmt.Errorf("no writable configuration directory: %w", errors.Join(errs...))
+}
+
+// writeStarter creates the directory of path and writes data to it
+func writeStarter(path string, data []byte, force bool) error {
+	if !force && fileExists(path) {
+		return fmt.Errorf("configuration already exists at '%s'", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("cannot create '%s': %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write '%s': %w", path, err)
+	}
+	return nil
+}
+
+// fileExists reports whether path exists
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// StarterConfig returns the example commands written by Init for the given platform
+func StarterConfig(goos string) *Config {
+	switch goos {
+	case "windows":
+		return &Config{Commands: map[string]Command{
+			"notepad": {Path: `C:\Windows\System32\notepad.exe`, Args: []string{}},
+			"cmd":     {Path: `C:\Windows\System32\cmd.exe`, Args: []string{}, Cwd: "~"},
+			"search":  {Path: `C:\Windows\explorer.exe`, Args: []string{"https://duckduckgo.com/?q={query}"}},
+		}}
+	case "darwin":
+		return &Config{Commands: map[string]Command{
+			"terminal": {Path: "/usr/bin/open", Args: []string{"-a", "Terminal"}},
+			"home":     {Path: "/usr/bin/open", Args: []string{"{home}"}},
+			"search":   {Path: "/usr/bin/open", Args: []string{"https://duckduckgo.com/?q={query}"}},
+		}}
+	default:
+		// Installed applications come from their .desktop entries
+		return &Config{
+			Desktop: &DesktopImport{Enabled: true},
+			Commands: map[string]Command{
+				"files":  {Path: "/usr/bin/xdg-open", Args: []string{"{home}"}},
+				"search": {Path: "/usr/bin/xdg-open", Args: []string{"https://duckduckgo.com/?q={query}"}},
+			},
+		}
+	}
+}
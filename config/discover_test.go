@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+// TestSearchDirs tests the configuration search order on each platform
+func TestSearchDirs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses Unix-style absolute paths")
+	}
+	home := func() (string, error) { return "/home/me", nil }
+	noHome := func() (string, error) { return "", errors.New("no home") }
+	env := func(vars map[string]string) func(string) string {
+		return func(key string) string { return vars[key] }
+	}
+
+	tests := []struct {
+		name     string
+		goos     string
+		getenv   func(string) string
+		home     func() (string, error)
+		expected []string
+	}{
+		{
+			name:     "linux defaults",
+			goos:     "linux",
+			getenv:   env(nil),
+			home:     home,
+			expected: []string{"/home/me/.config/launcher", "/etc/xdg/launcher"},
+		},
+		{
+			name: "linux with XDG variables",
+			goos: "linux",
+			getenv: env(map[string]string{
+				"XDG_CONFIG_HOME": "/xdg/home",
+				"XDG_CONFIG_DIRS": "/xdg/a" + string(os.PathListSeparator) + "relative" + string(os.PathListSeparator) + "/xdg/b",
+			}),
+			home:     home,
+			expected: []string{"/xdg/home/launcher", "/xdg/a/launcher", "/xdg/b/launcher"},
+		},
+		{
+			name:     "linux without home",
+			goos:     "linux",
+			getenv:   env(nil),
+			home:     noHome,
+			expected: []string{"/etc/xdg/launcher"},
+		},
+		{
+			name:     "windows uses APPDATA last",
+			goos:     "windows",
+			getenv:   env(map[string]string{"XDG_CONFIG_HOME": "/xdg/home", "APPDATA": "/appdata"}),
+			home:     home,
+			expected: []string{"/xdg/home/launcher", "/appdata/launcher"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var expected []string
+			for _, dir := range tt.expected {
+				expected = append(expected, filepath.FromSlash(dir))
+			}
+			if got := searchDirs(tt.goos, tt.getenv, tt.home); !reflect.DeepEqual(got, expected) {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+// setSearchDirs points the configuration search at temporary directories
+func setSearchDirs(t *testing.T) (configHome, configDir string) {
+	t.Helper()
+	configHome, configDir = t.TempDir(), t.TempDir()
+	t.Setenv(PathEnv, "")
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", configDir)
+	t.Setenv("APPDATA", "")
+	return configHome, configDir
+}
+
+// TestDefaultPath tests that the first existing configuration file is found
+func TestDefaultPath(t *testing.T) {
+	configHome, configDir := setSearchDirs(t)
+
+	if got, expected := DefaultPath(), filepath.Join(configHome, "launcher", "config.json"); got != expected {
+		t.Errorf("Expected %s without any configuration, got %s", expected, got)
+	}
+
+	systemConfig := filepath.Join(configDir, "launcher", "config.toml")
+	os.MkdirAll(filepath.Dir(systemConfig), 0755)
+	writeConfig(t, systemConfig, "")
+	if got := DefaultPath(); got != systemConfig {
+		t.Errorf("Expected system configuration %s, got %s", systemConfig, got)
+	}
+
+	userConfig := filepath.Join(configHome, "launcher", "config.yml")
+	os.MkdirAll(filepath.Dir(userConfig), 0755)
+	writeConfig(t, userConfig, "")
+	if got := DefaultPath(); got != userConfig {
+		t.Errorf("Expected user configuration %s to take precedence, got %s", userConfig, got)
+	}
+
+	t.Setenv(PathEnv, "/explicit/config.json")
+	if got := DefaultPath(); got != "/explicit/config.json" {
+		t.Errorf("Expected %s to override the search, got %s", PathEnv, got)
+	}
+}
+
+// TestInit tests writing a starter configuration
+func TestInit(t *testing.T) {
+	configHome, _ := setSearchDirs(t)
+
+	path, err := Init("", FormatYAML, false)
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if expected := filepath.Join(configHome, "launcher", "config.yaml"); path != expected {
+		t.Errorf("Expected starter at %s, got %s", expected, path)
+	}
+
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	if err := cm.Load(); err != nil {
+		t.Errorf("Starter configuration does not load: %v", err)
+	}
+	if DefaultPath() != path {
+		t.Errorf("Expected the starter to be found by DefaultPath, got %s", DefaultPath())
+	}
+
+	if _, err := Init("", FormatJSON, false); err == nil {
+		t.Error("Expected Init to refuse overwriting an existing configuration")
+	}
+	if _, err := Init("", FormatYAML, true); err != nil {
+		t.Errorf("Expected Init with force to overwrite, got: %v", err)
+	}
+
+	explicit := filepath.Join(t.TempDir(), "nested", "launcher.toml")
+	if path, err := Init(explicit, FormatTOML, false); err != nil || path != explicit {
+		t.Errorf("Expected starter at explicit path, got %s, %v", path, err)
+	}
+}
+
+// TestInitSkipsUnwritableDirectories tests that Init falls back to the next search directory
+func TestInitSkipsUnwritableDirectories(t *testing.T) {
+	_, configDir := setSearchDirs(t)
+
+	// A file where the first directory should be makes it unusable
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	writeConfig(t, blocked, "")
+	t.Setenv("XDG_CONFIG_HOME", blocked)
+
+	path, err := Init("", FormatJSON, false)
+	if err != nil {
+		t.Fatalf("Init failed: %v", err)
+	}
+	if expected := filepath.Join(configDir, "launcher", "config.json"); path != expected {
+		t.Errorf("Expected starter at %s, got %s", expected, path)
+	}
+}
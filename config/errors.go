@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ValidationError describes a single problem found in the configuration file.
+// Line and Column are 1-based positions in the file, or 0 if unknown. Some formats
+// only report the line of a syntax error.
+type ValidationError struct {
+	File    string `json:"file,omitempty"`    // Configuration file containing the problem
+	Command string `json:"command,omitempty"` // Name of the offending command, if any
+	Field   string `json:"field,omitempty"`   // Offending field, e.g. "path" or "env"
+	Line    int    `json:"line,omitempty"`    // Line in the configuration file
+	Column  int    `json:"column,omitempty"`  // Column in the configuration file
+	Message string `json:"message"`           // Description of the problem
+
+	path []string // Location in the document when it is more precise than Command and Field
+}
+
+// Error formats the problem as "file: line L, column C: command 'name' field 'f': message"
+func (e *ValidationError) Error() string {
+	var parts []string
+	if e.File != "" {
+		parts = append(parts, e.File)
+	}
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		parts = append(parts, fmt.Sprintf("line %d, column %d", e.Line, e.Column))
+	case e.Line > 0:
+		parts = append(parts, fmt.Sprintf("line %d", e.Line))
+	}
+
+	var subject []string
+	if e.Command != "" {
+		subject = append(subject, fmt.Sprintf("command '%s'", e.Command))
+	}
+	if e.Field != "" {
+		subject = append(subject, fmt.Sprintf("field '%s'", e.Field))
+	}
+	if len(subject) > 0 {
+		parts = append(parts, strings.Join(subject, " "))
+	}
+
+	return strings.Join(append(parts, e.Message), ": ")
+}
+
+// ValidationErrors is the list of every problem found in a configuration file,
+// ordered by position so that the result is the same on every run
+type ValidationErrors []*ValidationError
+
+// Error formats the problems as a list, one per line
+func (e ValidationErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns the individual problems so that errors.Is and errors.As see them
+func (e ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
+// sort orders the problems by file and position, then by command and field
+func (e ValidationErrors) sort() {
+	sort.SliceStable(e, func(i, j int) bool {
+		a, b := e[i], e[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Command != b.Command {
+			return a.Command < b.Command
+		}
+		if a.Field != b.Field {
+			return a.Field < b.Field
+		}
+		return a.Message < b.Message
+	})
+}
+
+// AsValidationErrors returns the problems contained in err. Errors that are not
+// validation errors (e.g. an unreadable file) are returned as a single entry.
+func AsValidationErrors(err error) ValidationErrors {
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if errors.As(err, &errs) {
+		return errs
+	}
+	return ValidationErrors{{Message: err.Error()}}
+}
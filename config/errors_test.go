@@ -0,0 +1,162 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestValidationErrorFormat tests the human-readable form of a single problem
+func TestValidationErrorFormat(t *testing.T) {
+	tests := []struct {
+		err      *ValidationError
+		expected string
+	}{
+		{&ValidationError{Message: "oops"}, "oops"},
+		{&ValidationError{Line: 3, Column: 5, Message: "oops"}, "line 3, column 5: oops"},
+		{&ValidationError{Line: 3, Message: "oops"}, "line 3: oops"},
+		{&ValidationError{Command: "chrome", Message: "oops"}, "command 'chrome': oops"},
+		{
+			&ValidationError{Command: "chrome", Field: "path", Line: 2, Column: 7, Message: "oops"},
+			"line 2, column 7: command 'chrome' field 'path': oops",
+		},
+		{
+			&ValidationError{File: "conf.d/web.json", Command: "chrome", Line: 2, Column: 7, Message: "oops"},
+			"conf.d/web.json: line 2, column 7: command 'chrome': oops",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+// TestLoadCollectsPositionedErrors tests that Load reports every problem with its position, in file order
+func TestLoadCollectsPositionedErrors(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "commands": {
+    "zeta": {"path": ""},
+    "good": {"path": "/bin/app"},
+    "alpha": {
+      "path": "/bin/app",
+      "env": {"A=B": "x"}
+    },
+    "": {"path": "/bin/app"}
+  }
+}`)
+
+	// The order must not depend on map iteration, so load several times
+	for i := 0; i < 5; i++ {
+		cm, err := NewConfigManager(configFile)
+		if err != nil {
+			t.Fatalf("Failed to create ConfigManager: %v", err)
+		}
+		err = cm.Load()
+
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected ValidationErrors, got %T: %v", err, err)
+		}
+
+		expected := []string{
+			configFile + ": line 3, column 14: command 'zeta' field 'path': must have a non-empty path",
+			configFile + `: line 7, column 15: command 'alpha' field 'env': invalid environment variable name "A=B"`,
+			configFile + ": line 9, column 5: command name cannot be empty",
+		}
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Fatalf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+		}
+	}
+}
+
+// TestLoadMissingFieldPointsAtCommand tests that a missing field is reported at its command
+func TestLoadMissingFieldPointsAtCommand(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, "{\"commands\": {\n  \"chrome\": {\"args\": []}\n}}")
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	errs := AsValidationErrors(cm.Load())
+	if len(errs) != 1 || errs[0].Line != 2 || errs[0].Column != 3 {
+		t.Errorf("Expected one error at line 2, column 3, got: %v", errs)
+	}
+}
+
+// TestLoadParseErrorPosition tests that JSON syntax and type errors carry a position
+func TestLoadParseErrorPosition(t *testing.T) {
+	tests := []struct {
+		name         string
+		content      string
+		line, column int
+	}{
+		{"syntax error", "{\n  \"commands\": {,}\n}", 2, 16},
+		{"wrong type", "{\n  \"commands\": {\n    \"a\": {\"path\": 42}\n  }\n}", 3, 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), "config.json")
+			writeConfig(t, configFile, tt.content)
+
+			cm, err := NewConfigManager(configFile)
+			if err != nil {
+				t.Fatalf("Failed to create ConfigManager: %v", err)
+			}
+			errs := AsValidationErrors(cm.Load())
+			if len(errs) != 1 {
+				t.Fatalf("Expected one error, got: %v", errs)
+			}
+			if errs[0].Line != tt.line || errs[0].Column != tt.column {
+				t.Errorf("Expected line %d, column %d, got: %v", tt.line, tt.column, errs[0])
+			}
+		})
+	}
+}
+
+// TestValidationErrorsJSON tests the machine-readable form of the problem list
+func TestValidationErrorsJSON(t *testing.T) {
+	errs := ValidationErrors{
+		{Command: "chrome", Field: "path", Line: 2, Column: 7, Message: "must have a non-empty path"},
+		{Message: "failed to read config file"},
+	}
+
+	data, err := json.Marshal(errs)
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	expected := `[{"command":"chrome","field":"path","line":2,"column":7,"message":"must have a non-empty path"},` +
+		`{"message":"failed to read config file"}]`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+// TestAsValidationErrors tests wrapping of errors that are not validation errors
+func TestAsValidationErrors(t *testing.T) {
+	if errs := AsValidationErrors(nil); errs != nil {
+		t.Errorf("Expected nil for nil error, got %v", errs)
+	}
+
+	errs := AsValidationErrors(fmt.Errorf("failed to read config file"))
+	if len(errs) != 1 || errs[0].Message != "failed to read config file" {
+		t.Errorf("Unexpected result: %v", errs)
+	}
+
+	original := ValidationErrors{{Command: "a", Message: "x"}}
+	wrapped := fmt.Errorf("reload: %w", original)
+	if errs := AsValidationErrors(wrapped); !reflect.DeepEqual(errs, original) {
+		t.Errorf("Expected original errors, got %v", errs)
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"app-launcher/logger"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format identifies the syntax of a configuration file
+type Format string
+
+// Supported configuration formats
+const (
+	FormatJSON Format = "json"
+	FormatTOML Format = "toml"
+	FormatYAML Format = "yaml"
+)
+
+// FormatFromPath picks the format from the file extension (.json, .toml, .yaml or .yml).
+// Files with any other extension are read as JSON, the original configuration format.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		return FormatTOML
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseFormat returns the format with the given name ("json", "toml", "yaml" or "yml")
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return FormatJSON, nil
+	case "toml":
+		return FormatTOML, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("unknown config format '%s' (expected json, toml or yaml)", name)
+	}
+}
+
+// Decode parses a configuration document without validating its commands.
+// Syntax errors, values that do not match the schema and duplicate commands are
+// returned as ValidationErrors. Unknown fields tolerated by a lenient document are logged.
+func Decode(data []byte, format Format) (*Config, error) {
+	cfg, positions, warnings, err := decode(data, format)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range warnings {
+		logger.Warn("Configuration warning: %v", w)
+	}
+
+	var errs ValidationErrors
+	for name := range cfg.Commands {
+		errs = append(errs, duplicateErrors(positions, name, cfg.AllowDuplicates)...)
+	}
+	if len(errs) > 0 {
+		errs.sort()
+		return nil, errs
+	}
+	return cfg, nil
+}
+
+// Encode writes a configuration in the given format
+func Encode(cfg *Config, format Format) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(cfg); err != nil {
+			return nil, err
+		}
+	case FormatTOML:
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return nil, err
+		}
+	case FormatYAML:
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(cfg); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown config format '%s'", format)
+	}
+	return buf.Bytes(), nil
+}
+
+// decode parses a configuration document and indexes the position of its values.
+// TOML documents carry no positions beyond syntax errors, so their index is empty.
+//
+// The document is checked against the schema before it is decoded into a Config, so
+// that every type error is reported rather than the first. Unknown fields are
+// returned as warnings if the document is lenient.
+func decode(data []byte, format Format) (*Config, *positionIndex, ValidationErrors, error) {
+	var cfg Config
+	var doc any
+	var root yaml.Node
+	var positions *positionIndex
+	switch format {
+	case FormatTOML:
+		var table map[string]any
+		if _, err := toml.Decode(string(data), &table); err != nil {
+			return nil, nil, nil, tomlParseError(err)
+		}
+		doc, positions = table, newPositionIndex()
+
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &root); err != nil {
+			return nil, nil, nil, yamlParseError(err)
+		}
+		if root.Kind == 0 {
+			// An empty document decodes to nothing, like an empty JSON object
+			return &cfg, newPositionIndex(), nil, nil
+		}
+		if err := root.Decode(&doc); err != nil {
+			return nil, nil, nil, yamlParseError(err)
+		}
+		positions = indexYAML(&root)
+
+	default:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, nil, nil, jsonParseError(data, err)
+		}
+		positions = indexJSON(data)
+	}
+
+	lenient := false
+	if members, ok := doc.(map[string]any); ok {
+		lenient, _ = members["lenient"].(bool)
+	}
+	errs, warnings := schemaErrors(checkSchema(configSchema, doc, nil), positions, lenient)
+	if len(errs) > 0 {
+		errs.sort()
+		return nil, nil, nil, errs
+	}
+
+	switch format {
+	case FormatTOML:
+		if _, err := toml.Decode(string(data), &cfg); err != nil {
+			return nil, nil, nil, tomlParseError(err)
+		}
+	case FormatYAML:
+		if err := root.Decode(&cfg); err != nil {
+			return nil, nil, nil, yamlParseError(err)
+		}
+	default:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return nil, nil, nil, jsonParseError(data, err)
+		}
+	}
+	return &cfg, positions, warnings, nil
+}
+
+// jsonParseError converts a JSON decoding error into a positioned validation error
+func jsonParseError(data []byte, err error) error {
+	problem := &ValidationError{Message: fmt.Sprintf("failed to parse config file: %v", err)}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset counts the offending character, so step back onto it
+		offset := int(syntaxErr.Offset) - 1
+		if offset < 0 {
+			offset = 0
+		}
+		pos := offsetPosition(data, offset)
+		problem.Line, problem.Column = pos.line, pos.column
+	case errors.As(err, &typeErr):
+		// The offset points just past the offending value, so report where it starts
+		problem.Line, problem.Column = indexJSON(data).lookup(strings.Split(typeErr.Field, ".")...)
+	}
+	return ValidationErrors{problem}
+}
+
+// tomlParseError converts a TOML decoding error into a positioned validation error
+func tomlParseError(err error) error {
+	problem := &ValidationError{Message: fmt.Sprintf("failed to parse config file: %v", err)}
+
+	var parseErr toml.ParseError
+	if errors.As(err, &parseErr) {
+		problem.Message = fmt.Sprintf("failed to parse config file: %s", parseErr.Message)
+		problem.Line, problem.Column = parseErr.Position.Line, parseErr.Position.Col
+	} else if m := tomlLinePattern.FindStringSubmatch(err.Error()); m != nil {
+		// Type mismatches are plain errors that only mention the line
+		problem.Line, _ = strconv.Atoi(m[1])
+		problem.Message = "failed to parse config file: " + m[2]
+	}
+	return ValidationErrors{problem}
+}
+
+// tomlLinePattern extracts the line number from TOML type mismatch errors
+var tomlLinePattern = regexp.MustCompile(`^toml: line (\d+)(?: \(last key "[^"]*"\))?: (.*)$`)
+
+// yamlLinePattern extracts the line number from yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlParseError converts a YAML decoding error into validation errors with line numbers.
+// Type errors may list several problems, which are reported individually.
+func yamlParseError(err error) error {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	var errs ValidationErrors
+	for _, message := range messages {
+		problem := &ValidationError{Message: "failed to parse config file: " + message}
+		if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+			problem.Line, _ = strconv.Atoi(m[1])
+			problem.Message = "failed to parse config file: " + m[2]
+		}
+		errs = append(errs, problem)
+	}
+	errs.sort()
+	return errs
+}
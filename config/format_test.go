@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestFormatFromPath tests that the decoder is picked from the file extension
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]Format{
+		"config.json":   FormatJSON,
+		"config.toml":   FormatTOML,
+		"config.yaml":   FormatYAML,
+		"config.YML":    FormatYAML,
+		"config":        FormatJSON,
+		"dir.toml/conf": FormatJSON,
+	}
+	for path, expected := range tests {
+		if got := FormatFromPath(path); got != expected {
+			t.Errorf("FormatFromPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+
+	if _, err := ParseFormat("ini"); err == nil {
+		t.Error("Expected error for unknown format")
+	}
+}
+
+// TestLoadAllFormats tests that the same configuration loads identically from every format
+func TestLoadAllFormats(t *testing.T) {
+	load := func(path string) map[string]Command {
+		cm, err := NewConfigManager(path)
+		if err != nil {
+			t.Fatalf("Failed to create ConfigManager: %v", err)
+		}
+		if err := cm.Load(); err != nil {
+			t.Fatalf("Failed to load %s: %v", path, err)
+		}
+		commands := make(map[string]Command)
+		for _, name := range cm.CommandNames() {
+			commands[name], _ = cm.GetCommand(name)
+		}
+		return commands
+	}
+
+	expected := load("../testdata/valid_config.json")
+	for _, path := range []string{"../testdata/valid_config.toml", "../testdata/valid_config.yaml"} {
+		if got := load(path); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s loaded differently:\n got: %+v\nwant: %+v", path, got, expected)
+		}
+	}
+}
+
+// TestLoadValidatesAllFormats tests that TOML and YAML go through the same validation
+func TestLoadValidatesAllFormats(t *testing.T) {
+	tests := []struct {
+		file         string
+		content      string
+		line, column int
+	}{
+		{"config.yaml", "commands:\n  chrome:\n    path: \"\"\n", 3, 5},
+		{"config.toml", "[commands.chrome]\npath = \"\"\n", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), tt.file)
+			writeConfig(t, configFile, tt.content)
+
+			cm, err := NewConfigManager(configFile)
+			if err != nil {
+				t.Fatalf("Failed to create ConfigManager: %v", err)
+			}
+			errs := AsValidationErrors(cm.Load())
+			if len(errs) != 1 || errs[0].Command != "chrome" || errs[0].Field != "path" {
+				t.Fatalf("Expected an empty path error, got: %v", errs)
+			}
+			if errs[0].Line != tt.line || errs[0].Column != tt.column {
+				t.Errorf("Expected line %d, column %d, got: %v", tt.line, tt.column, errs[0])
+			}
+		})
+	}
+}
+
+// TestLoadParseErrorsAllFormats tests that syntax errors carry a line in every format
+func TestLoadParseErrorsAllFormats(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+		line    int
+	}{
+		{"config.yaml", "commands:\n  chrome:\n\tpath: a\n", 3},
+		{"config.yaml", "commands:\n  chrome: {path: a}\n  chrome: {path: b}\n", 3},
+		{"config.toml", "[commands.chrome]\npath = \"a\"\npath = \"b\"\n", 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			configFile := filepath.Join(t.TempDir(), tt.file)
+			writeConfig(t, configFile, tt.content)
+
+			cm, err := NewConfigManager(configFile)
+			if err != nil {
+				t.Fatalf("Failed to create ConfigManager: %v", err)
+			}
+			errs := AsValidationErrors(cm.Load())
+			if len(errs) != 1 || errs[0].Line != tt.line {
+				t.Errorf("Expected one error on line %d, got: %v", tt.line, errs)
+			}
+		})
+	}
+}
+
+// TestEncodeRoundTrip tests that converting between formats does not lose data
+func TestEncodeRoundTrip(t *testing.T) {
+	original := &Config{
+		AllowDuplicates: true,
+		Commands: map[string]Command{
+			"chrome": {Path: `C:\Program Files\Google\Chrome\Application\chrome.exe`, Args: []string{}},
+			"search engine": {
+				Path:      "/usr/bin/firefox",
+				Args:      []string{"--new-tab", "https://example.com/?q={query}&lang=en"},
+				AllowArgs: true,
+				Cwd:       "~/work",
+				Env:       map[string]string{"MOZ_ENABLE_WAYLAND": "1", "LANG": "C"},
+				EnvClear:  true,
+			},
+		},
+	}
+
+	formats := []Format{FormatJSON, FormatTOML, FormatYAML}
+	for _, from := range formats {
+		for _, to := range formats {
+			data, err := Encode(original, from)
+			if err != nil {
+				t.Fatalf("Encode(%s) failed: %v", from, err)
+			}
+			decoded, err := Decode(data, from)
+			if err != nil {
+				t.Fatalf("Decode(%s) failed: %v\n%s", from, err, data)
+			}
+			data, err = Encode(decoded, to)
+			if err != nil {
+				t.Fatalf("Encode(%s) failed: %v", to, err)
+			}
+			converted, err := Decode(data, to)
+			if err != nil {
+				t.Fatalf("Decode(%s) failed: %v\n%s", to, err, data)
+			}
+			if !reflect.DeepEqual(converted, original) {
+				t.Errorf("%s -> %s lost data:\n got: %+v\nwant: %+v", from, to, converted, original)
+			}
+		}
+	}
+}
+
+// TestDecodeRejectsDuplicates tests that conversion refuses to drop duplicate commands
+func TestDecodeRejectsDuplicates(t *testing.T) {
+	data, err := os.ReadFile("../testdata/valid_config.json")
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	if _, err := Decode(data, FormatJSON); err != nil {
+		t.Fatalf("Expected fixture to decode, got: %v", err)
+	}
+
+	if _, err := Decode([]byte(`{"commands": {"a": {"path": "x"}, "a": {"path": "y"}}}`), FormatJSON); err == nil {
+		t.Error("Expected duplicate commands to be rejected")
+	}
+}
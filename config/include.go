@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// layer is one configuration file taking part in a Load
+type layer struct {
+	path      string         // Absolute path of the file
+	cfg       *Config        // Decoded contents
+	positions *positionIndex // Positions of the values in the file
+}
+
+// includeLoader collects the configuration files reachable from the main file.
+//
+// Precedence: the files named by "include" are loaded in the order listed (glob
+// matches in lexical order), each after the files it includes itself. Later files
+// override commands of earlier ones, and a file always overrides what it includes,
+// so the main configuration file has the final say.
+type includeLoader struct {
+	layers   []layer         // Loaded files, lowest precedence first
+	loaded   map[string]bool // Files already loaded, to load each file only once
+	sources  []string        // Every file read, including ones that failed to load
+	patterns []string        // Absolute include patterns, to pick up new matching files
+	errs     ValidationErrors
+	warnings ValidationErrors
+}
+
+// newIncludeLoader creates an empty includeLoader
+func newIncludeLoader() *includeLoader {
+	return &includeLoader{loaded: make(map[string]bool)}
+}
+
+// load decodes the file at path and, before it, every file it includes.
+// stack holds the chain of files currently being included, for cycle detection.
+func (l *includeLoader) load(path string, data []byte, stack []string) {
+	l.loaded[path] = true
+	l.sources = append(l.sources, path)
+
+	cfg, positions, warnings, err := decode(data, FormatFromPath(path))
+	if err != nil {
+		l.fail(path, err)
+		return
+	}
+	for _, w := range warnings {
+		w.File = path
+		l.warnings = append(l.warnings, w)
+	}
+
+	stack = append(stack[:len(stack):len(stack)], path)
+	for i, pattern := range cfg.Include {
+		matches, err := l.resolveInclude(pattern, filepath.Dir(path))
+		if err != nil {
+			l.includeError(path, positions, i, err.Error())
+			continue
+		}
+		for _, match := range matches {
+			if cycle := includeCycle(stack, match); cycle != "" {
+				l.includeError(path, positions, i, "include cycle: "+cycle)
+				continue
+			}
+			if l.loaded[match] {
+				continue
+			}
+			included, err := os.ReadFile(match)
+			if err != nil {
+				l.includeError(path, positions, i, fmt.Sprintf("failed to read included file: %v", err))
+				continue
+			}
+			l.load(match, included, stack)
+		}
+	}
+
+	l.layers = append(l.layers, layer{path: path, cfg: cfg, positions: positions})
+}
+
+// resolveInclude expands an include entry relative to the including file's directory.
+// Patterns with glob characters may match nothing; plain paths must exist.
+func (l *includeLoader) resolveInclude(pattern, baseDir string) ([]string, error) {
+	path, err := resolvePath(pattern, baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include '%s': %w", pattern, err)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		return []string{path}, nil
+	}
+
+	l.patterns = append(l.patterns, path)
+	matches, err := filepath.Glob(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern '%s': %w", pattern, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
+
+// includeCycle returns the include chain ending in path if path is already being
+// included, or "" if including it is safe
+func includeCycle(stack []string, path string) string {
+	for i, file := range stack {
+		if file == path {
+			return strings.Join(append(stack[i:len(stack):len(stack)], path), " -> ")
+		}
+	}
+	return ""
+}
+
+// fail records an error that prevented a file from being decoded
+func (l *includeLoader) fail(path string, err error) {
+	for _, e := range AsValidationErrors(err) {
+		e.File = path
+		l.errs = append(l.errs, e)
+	}
+}
+
+// includeError records a problem with the i-th include entry of a file
+func (l *includeLoader) includeError(path string, positions *positionIndex, i int, message string) {
+	e := &ValidationError{File: path, Field: "include", Message: message}
+	e.Line, e.Column = positions.lookup("include", strconv.Itoa(i))
+	l.errs = append(l.errs, e)
+}
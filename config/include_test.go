@@ -0,0 +1,167 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// loadConfig creates a ConfigManager for path and loads it
+func loadConfig(t *testing.T, path string) (*ConfigManager, error) {
+	t.Helper()
+	cm, err := NewConfigManager(path)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	return cm, cm.Load()
+}
+
+// TestIncludePrecedence tests that includes are merged in order and the including file wins
+func TestIncludePrecedence(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "conf.d"), 0755); err != nil {
+		t.Fatalf("Failed to create conf.d: %v", err)
+	}
+	writeConfig(t, filepath.Join(dir, "team.json"), `{"commands": {
+		"shared": {"path": "/team/shared"},
+		"editor": {"path": "/team/editor"},
+		"browser": {"path": "/team/browser"}
+	}}`)
+	writeConfig(t, filepath.Join(dir, "conf.d", "10-a.yaml"), "commands:\n  editor:\n    path: /a/editor\n  browser:\n    path: /a/browser\n")
+	writeConfig(t, filepath.Join(dir, "conf.d", "20-b.toml"), "[commands.browser]\npath = \"/b/browser\"\n")
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{
+  "include": ["team.json", "conf.d/*"],
+  "commands": {"personal": {"path": "/me/personal"}, "shared": {"path": "/me/shared"}}
+}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	expected := map[string]struct{ path, origin string }{
+		"shared":   {"/me/shared", mainFile},
+		"personal": {"/me/personal", mainFile},
+		"editor":   {"/a/editor", filepath.Join(dir, "conf.d", "10-a.yaml")},
+		"browser":  {"/b/browser", filepath.Join(dir, "conf.d", "20-b.toml")},
+	}
+	for name, want := range expected {
+		cmd, exists := cm.GetCommand(name)
+		if !exists || cmd.Path != want.path {
+			t.Errorf("Command '%s': expected path %s, got %+v", name, want.path, cmd)
+		}
+		if origin, _ := cm.Origin(name); origin != want.origin {
+			t.Errorf("Command '%s': expected origin %s, got %s", name, want.origin, origin)
+		}
+	}
+}
+
+// TestIncludeResolvesPathsPerFile tests that relative paths in an included file are
+// resolved against that file's directory
+func TestIncludeResolvesPathsPerFile(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.MkdirAll(filepath.Join(sub, "work"), 0755); err != nil {
+		t.Fatalf("Failed to create directories: %v", err)
+	}
+	writeConfig(t, filepath.Join(sub, "more.json"), `{"commands": {"tool": {"path": "/bin/tool", "cwd": "work"}}}`)
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{"include": ["sub/more.json"], "commands": {}}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("tool"); cmd.Cwd != filepath.Join(sub, "work") {
+		t.Errorf("Expected cwd relative to the included file, got %s", cmd.Cwd)
+	}
+}
+
+// TestIncludeCycle tests that include cycles are reported instead of recursing forever
+func TestIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	writeConfig(t, a, `{"include": ["b.json"], "commands": {}}`)
+	writeConfig(t, b, "{\n  \"include\": [\"a.json\"],\n  \"commands\": {}\n}")
+
+	_, err := loadConfig(t, a)
+	errs := AsValidationErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one cycle error, got: %v", errs)
+	}
+	e := errs[0]
+	if e.File != b || e.Field != "include" || e.Line != 2 || e.Column != 15 {
+		t.Errorf("Expected error at the include entry of b.json, got: %v", e)
+	}
+	if !strings.Contains(e.Message, a+" -> "+b+" -> "+a) {
+		t.Errorf("Expected the cycle to be spelled out, got: %s", e.Message)
+	}
+}
+
+// TestIncludeErrorsNameTheirFile tests that problems in included files name that file
+func TestIncludeErrorsNameTheirFile(t *testing.T) {
+	dir := t.TempDir()
+	included := filepath.Join(dir, "broken.json")
+	writeConfig(t, included, `{"commands": {"bad": {"path": ""}}}`)
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{"include": ["broken.json", "missing.json", "none/*.json"], "commands": {}}`)
+
+	_, err := loadConfig(t, mainFile)
+	errs := AsValidationErrors(err)
+	if len(errs) != 1 || errs[0].File != mainFile || !strings.Contains(errs[0].Message, "failed to read included file") {
+		t.Fatalf("Expected the missing include to be reported, got: %v", errs)
+	}
+
+	writeConfig(t, mainFile, `{"include": ["broken.json", "none/*.json"], "commands": {}}`)
+	_, err = loadConfig(t, mainFile)
+	errs = AsValidationErrors(err)
+	if len(errs) != 1 || errs[0].File != included || errs[0].Command != "bad" {
+		t.Fatalf("Expected the error to name the included file, got: %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), included+": ") {
+		t.Errorf("Error message should start with the file, got: %s", errs[0].Error())
+	}
+}
+
+// TestWatchReloadsIncludedFiles tests that changes to included files and new glob matches trigger a reload
+func TestWatchReloadsIncludedFiles(t *testing.T) {
+	dir := t.TempDir()
+	confDir := filepath.Join(dir, "conf.d")
+	if err := os.Mkdir(confDir, 0755); err != nil {
+		t.Fatalf("Failed to create conf.d: %v", err)
+	}
+	writeConfig(t, filepath.Join(confDir, "a.json"), `{"commands": {"a": {"path": "/bin/a"}}}`)
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{"include": ["conf.d/*.json"], "commands": {}}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+	reloaded := make(chan error, 10)
+	if err := cm.Watch(func(err error) { reloaded <- err }); err != nil {
+		t.Fatalf("Failed to watch configuration: %v", err)
+	}
+	defer cm.Close()
+
+	writeConfig(t, filepath.Join(confDir, "b.json"), `{"commands": {"b": {"path": "/bin/b"}}}`)
+	if err := waitForReload(t, reloaded); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if _, exists := cm.GetCommand("b"); !exists {
+		t.Error("Expected command from the new file to be loaded")
+	}
+
+	time.Sleep(2 * reloadDebounce)
+	writeConfig(t, filepath.Join(confDir, "a.json"), `{"commands": {"a": {"path": "/bin/a2"}}}`)
+	if err := waitForReload(t, reloaded); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("a"); cmd.Path != "/bin/a2" {
+		t.Errorf("Expected changed included file to be reloaded, got %+v", cmd)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Single-instance policies: what launching a command does while a process the
+// launcher started for it is still running
+const (
+	SingleInstanceIgnore   = "ignore"   // Do nothing
+	SingleInstanceNotify   = "notify"   // Report that the command is already running
+	SingleInstanceActivate = "activate" // Run the command named by Activate instead
+)
+
+// validateSingleInstance checks that a command's Activate goes with the activate policy
+func validateSingleInstance(name string, cmd *Command) ValidationErrors {
+	switch {
+	case cmd.SingleInstance == SingleInstanceActivate && cmd.Activate == "":
+		return ValidationErrors{{Command: name, Field: "activate", Message: "single_instance 'activate' requires an activate command"}}
+	case cmd.SingleInstance != SingleInstanceActivate && cmd.Activate != "":
+		return ValidationErrors{{Command: name, Field: "activate", Message: "activate requires \"single_instance\": \"activate\""}}
+	}
+	return nil
+}
+
+// resolveActivate replaces the activate command of every command by the name it
+// refers to, which may be an alias, and reports references to unknown commands.
+// It runs once every file has been merged and desktop entries have been imported,
+// since the command may come from another file or be an installed application.
+func resolveActivate(commands map[string]Command, defined map[string]layer, names *nameTable) ValidationErrors {
+	var errs ValidationErrors
+	for name, cmd := range commands {
+		if cmd.Activate == "" {
+			continue
+		}
+		target, ok := names.resolve(cmd.Activate)
+		message := ""
+		switch {
+		case !ok:
+			message = fmt.Sprintf("activate command '%s' not found", cmd.Activate)
+		case target == name:
+			message = "a command cannot activate itself"
+		case commands[target].Steps != nil:
+			message = fmt.Sprintf("activate command '%s' is a macro", target)
+		default:
+			cmd.Activate = target
+			commands[name] = cmd
+			continue
+		}
+
+		l := defined[name]
+		e := &ValidationError{File: l.path, Command: name, Field: "activate", Message: message}
+		e.Line, e.Column = l.positions.lookup(fieldPath(e)...)
+		errs = append(errs, e)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Command < errs[j].Command })
+	return errs
+}
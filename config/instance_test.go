@@ -0,0 +1,77 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLoadResolvesActivateCommands tests that activate commands may be named by an
+// alias or come from another file and are stored under the configured name
+func TestLoadResolvesActivateCommands(t *testing.T) {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "config.json")
+	writeConfig(t, mainFile, `{
+  "include": ["focus.json"],
+  "commands": {
+    "code": {"path": "/bin/code", "single_instance": "activate", "activate": "fc"},
+    "term": {"path": "/bin/term", "single_instance": "notify"}
+  }
+}`)
+	writeConfig(t, filepath.Join(dir, "focus.json"), `{"commands": {"focus-code": {"path": "/bin/wmctrl", "args": ["-ia", "{pid}"], "aliases": ["fc"]}}}`)
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("code"); cmd.SingleInstance != SingleInstanceActivate || cmd.Activate != "focus-code" {
+		t.Errorf("Expected activate to resolve to 'focus-code', got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("term"); cmd.SingleInstance != SingleInstanceNotify || cmd.Activate != "" {
+		t.Errorf("Unexpected single-instance settings: %+v", cmd)
+	}
+}
+
+// TestLoadRejectsInvalidSingleInstance tests that the policy and the activate command
+// must go together and that the activate command must exist
+func TestLoadRejectsInvalidSingleInstance(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "commands": {
+    "a": {"path": "/bin/a", "single_instance": "activate"},
+    "b": {"path": "/bin/b", "activate": "a"}
+  }
+}`)
+	cm, _ := loadConfig(t, configFile)
+	expected := []string{
+		"line 3, column 5: command 'a' field 'activate': single_instance 'activate' requires an activate command",
+		`line 4, column 29: command 'b' field 'activate': activate requires "single_instance": "activate"`,
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+
+	writeConfig(t, configFile, `{
+  "commands": {
+    "a": {"path": "/bin/a", "single_instance": "activate", "activate": "missing"},
+    "b": {"path": "/bin/b", "single_instance": "activate", "activate": "bb", "aliases": ["bb"]}
+  }
+}`)
+	expected = []string{
+		"line 3, column 60: command 'a' field 'activate': activate command 'missing' not found",
+		"line 4, column 60: command 'b' field 'activate': a command cannot activate itself",
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+}
+
+// loadErrors reloads the configuration and returns its errors without the file name
+func loadErrors(cm *ConfigManager, configFile string) []string {
+	var got []string
+	for _, e := range AsValidationErrors(cm.Load()) {
+		got = append(got, strings.TrimPrefix(e.Error(), configFile+": "))
+	}
+	return got
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// What a macro waits for before it runs the next step
+const (
+	WaitStart = "start" // The step has started (the default)
+	WaitExit  = "exit"  // The step's process has exited
+)
+
+// What a macro does when a step fails
+const (
+	OnErrorAbort    = "abort"    // Skip the remaining steps (the default)
+	OnErrorContinue = "continue" // Run the next step anyway
+)
+
+// Step is one step of a macro command.
+//
+// Example JSON:
+//
+//	{
+//	  "commands": {
+//	    "standup": {
+//	      "steps": [
+//	        {"command": "browser", "args": ["https://example.com/board"]},
+//	        {"command": "meet", "delay": "2s"},
+//	        {"path": "/usr/bin/gedit", "args": ["~/notes/standup.md"], "on_error": "continue"}
+//	      ]
+//	    }
+//	  }
+//	}
+//
+// A step runs exactly one of Command, Path or Shell:
+//   - Command: Another configured command or alias, launched as if it was typed with
+//     Args as the typed arguments. A macro runs the steps of another macro to the end
+//     before it continues.
+//   - Path: An executable run with Args, like the path of a command.
+//   - Shell: A command line run by the macro's interpreter, like the shell of a command.
+//
+// Path and Shell steps use the cwd, env and interpreter of the macro.
+//
+// Wait is "start" to run the next step as soon as this one has started, or "exit" to
+// wait for its process to exit; a non-zero exit status then counts as a failure.
+// Delay pauses before the step, e.g. "500ms". OnError is "abort" to skip the
+// remaining steps when the step fails, or "continue".
+type Step struct {
+	Command string   `json:"command,omitempty" toml:"command,omitempty" yaml:"command,omitempty"`    // Configured command to run
+	Path    string   `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`             // Executable to run
+	Shell   string   `json:"shell,omitempty" toml:"shell,omitempty" yaml:"shell,omitempty"`          // Command line to run
+	Args    []string `json:"args,omitempty" toml:"args,omitempty" yaml:"args,omitempty"`             // Arguments of Path, or typed arguments of Command
+	Wait    string   `json:"wait,omitempty" toml:"wait,omitempty" yaml:"wait,omitempty"`             // What to wait for before the next step
+	Delay   string   `json:"delay,omitempty" toml:"delay,omitempty" yaml:"delay,omitempty"`          // Pause before the step
+	OnError string   `json:"on_error,omitempty" toml:"on_error,omitempty" yaml:"on_error,omitempty"` // Whether a failure stops the macro
+}
+
+// validateSteps checks the steps of a macro and that it sets no field that only
+// applies to a single process
+func validateSteps(name string, cmd *Command) ValidationErrors {
+	if cmd.Steps == nil {
+		return nil
+	}
+
+	var errs ValidationErrors
+	conflicts := []struct {
+		field string
+		set   bool
+	}{
+		{"path", cmd.Path != ""},
+		{"shell", cmd.Shell != ""},
+		{"args", len(cmd.Args) > 0},
+		{"allow_args", cmd.AllowArgs},
+		{"single_instance", cmd.SingleInstance != ""},
+		{"output", cmd.Output != ""},
+	}
+	for _, conflict := range conflicts {
+		if conflict.set {
+			errs = append(errs, &ValidationError{Command: name, Field: conflict.field, Message: conflict.field + " cannot be combined with steps"})
+		}
+	}
+	if len(cmd.Steps) == 0 {
+		errs = append(errs, &ValidationError{Command: name, Field: "steps", Message: "a macro needs at least one step"})
+	}
+
+	for i, step := range cmd.Steps {
+		path := []string{"commands", name, "steps", strconv.Itoa(i)}
+		stepError := func(message string) {
+			errs = append(errs, &ValidationError{Command: name, Field: "steps", Message: fmt.Sprintf("step %d: %s", i+1, message), path: path})
+		}
+
+		forms := 0
+		for _, value := range []string{step.Command, step.Path, step.Shell} {
+			if value != "" {
+				forms++
+			}
+		}
+		if forms != 1 {
+			stepError("needs exactly one of command, path or shell")
+		}
+		if step.Shell != "" && len(step.Args) > 0 {
+			stepError("args cannot be combined with shell")
+		}
+		if step.Delay != "" {
+			if delay, err := time.ParseDuration(step.Delay); err != nil || delay < 0 {
+				stepError(fmt.Sprintf("invalid delay %q, expected a duration such as \"2s\"", step.Delay))
+			}
+		}
+	}
+	return errs
+}
+
+// resolveSteps replaces the command of every macro step by the name it refers to,
+// which may be an alias, and reports references to unknown commands and macros
+// that run themselves, directly or through other macros. Like resolveActivate it
+// runs once every file has been merged and desktop entries have been imported.
+func resolveSteps(commands map[string]Command, defined map[string]layer, names *nameTable) ValidationErrors {
+	var errs ValidationErrors
+	stepError := func(name string, path []string, message string) {
+		l := defined[name]
+		e := &ValidationError{File: l.path, Command: name, Field: "steps", Message: message, path: path}
+		e.Line, e.Column = l.positions.lookup(fieldPath(e)...)
+		errs = append(errs, e)
+	}
+
+	var macros []string
+	for name, cmd := range commands {
+		if cmd.Steps == nil {
+			continue
+		}
+		macros = append(macros, name)
+		for i, step := range cmd.Steps {
+			if step.Command == "" {
+				continue
+			}
+			target, ok := names.resolve(step.Command)
+			if !ok {
+				path := []string{"commands", name, "steps", strconv.Itoa(i), "command"}
+				stepError(name, path, fmt.Sprintf("step %d: command '%s' not found", i+1, step.Command))
+				continue
+			}
+			cmd.Steps[i].Command = target
+		}
+	}
+	if len(errs) > 0 {
+		errs.sort()
+		return errs
+	}
+
+	// Depth-first search for a macro reachable from itself. Each cycle is reported
+	// once, on the macro through which the search entered it.
+	sort.Strings(macros)
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	var stack []string
+	var visit func(name string)
+	visit = func(name string) {
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, step := range commands[name].Steps {
+			if commands[step.Command].Steps == nil {
+				continue
+			}
+			switch state[step.Command] {
+			case unvisited:
+				visit(step.Command)
+			case visiting:
+				start := 0
+				for stack[start] != step.Command {
+					start++
+				}
+				cycle := append(append([]string(nil), stack[start:]...), step.Command)
+				stepError(step.Command, []string{"commands", step.Command, "steps"}, "steps run the macro itself: "+strings.Join(cycle, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, name := range macros {
+		if state[name] == unvisited {
+			visit(name)
+		}
+	}
+	return errs
+}
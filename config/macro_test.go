@@ -0,0 +1,99 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadMacro tests that a macro loads with its step commands resolved to
+// configured names and the interpreter of its file
+func TestLoadMacro(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, configFile, `interpreter: [bash, -c]
+commands:
+  browser:
+    path: /usr/bin/firefox
+    allow_args: true
+    aliases: [b]
+  standup:
+    steps:
+      - command: b
+        args: [https://example.com/board]
+      - shell: sleep 1 && notify-send hi
+        delay: 500ms
+        wait: exit
+        on_error: continue
+`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	cmd, _ := cm.GetCommand("standup")
+	expected := []Step{
+		{Command: "browser", Args: []string{"https://example.com/board"}},
+		{Shell: "sleep 1 && notify-send hi", Delay: "500ms", Wait: WaitExit, OnError: OnErrorContinue},
+	}
+	if !reflect.DeepEqual(cmd.Steps, expected) || !reflect.DeepEqual(cmd.Interpreter, []string{"bash", "-c"}) {
+		t.Errorf("Unexpected macro: %+v", cmd)
+	}
+}
+
+// TestLoadRejectsInvalidMacros tests the checks of single macros
+func TestLoadRejectsInvalidMacros(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "commands": {
+    "mixed": {"path": "/bin/ls", "single_instance": "ignore", "steps": [{"command": "mixed"}]},
+    "empty": {"steps": []},
+    "steps": {"steps": [{"command": "a", "path": "/bin/ls"}, {"shell": "ls", "args": ["-l"]}, {"path": "/bin/ls", "delay": "soon"}]}
+  }
+}`)
+	cm, _ := loadConfig(t, configFile)
+	expected := []string{
+		"line 3, column 15: command 'mixed' field 'path': path cannot be combined with steps",
+		"line 3, column 34: command 'mixed' field 'single_instance': single_instance cannot be combined with steps",
+		"line 4, column 15: command 'empty' field 'steps': a macro needs at least one step",
+		"line 5, column 25: command 'steps' field 'steps': step 1: needs exactly one of command, path or shell",
+		"line 5, column 62: command 'steps' field 'steps': step 2: args cannot be combined with shell",
+		`line 5, column 95: command 'steps' field 'steps': step 3: invalid delay "soon", expected a duration such as "2s"`,
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+}
+
+// TestLoadRejectsMacroReferences tests that steps must name existing commands and
+// that macros may not run themselves
+func TestLoadRejectsMacroReferences(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "commands": {
+    "a": {"steps": [{"command": "missing"}]}
+  }
+}`)
+	cm, _ := loadConfig(t, configFile)
+	expected := []string{"line 3, column 22: command 'a' field 'steps': step 1: command 'missing' not found"}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+
+	writeConfig(t, configFile, `{
+  "commands": {
+    "a": {"steps": [{"command": "tool"}, {"command": "bb"}]},
+    "b": {"aliases": ["bb"], "steps": [{"command": "c"}]},
+    "c": {"steps": [{"command": "a"}]},
+    "d": {"steps": [{"command": "d"}]},
+    "tool": {"path": "/bin/tool", "single_instance": "activate", "activate": "d"}
+  }
+}`)
+	expected = []string{
+		"line 3, column 11: command 'a' field 'steps': steps run the macro itself: a -> b -> c -> a",
+		"line 6, column 11: command 'd' field 'steps': steps run the macro itself: d -> d",
+		"line 7, column 66: command 'tool' field 'activate': activate command 'd' is a macro",
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+}
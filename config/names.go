@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// nameTable maps every name a command can be invoked by, its own name and its
+// aliases, to the command name. With caseInsensitive the keys are lower-cased.
+type nameTable struct {
+	caseInsensitive bool
+	names           map[string]string // Lookup key to command name
+	owners          map[string]string // Lookup key to a description of what claimed it, for errors
+}
+
+// newNameTable creates an empty nameTable
+func newNameTable(caseInsensitive bool) *nameTable {
+	return &nameTable{
+		caseInsensitive: caseInsensitive,
+		names:           make(map[string]string),
+		owners:          make(map[string]string),
+	}
+}
+
+// key returns the lookup key of a name
+func (t *nameTable) key(name string) string {
+	if t.caseInsensitive {
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+// add registers name for command. If the name is already taken, nothing is changed
+// and the description of the current owner is returned.
+func (t *nameTable) add(name, command, owner string) (string, bool) {
+	key := t.key(name)
+	if existing, taken := t.owners[key]; taken {
+		return existing, false
+	}
+	t.names[key] = command
+	t.owners[key] = owner
+	return "", true
+}
+
+// resolve returns the command that name refers to. Surrounding whitespace is ignored.
+func (t *nameTable) resolve(name string) (string, bool) {
+	command, ok := t.names[t.key(strings.TrimSpace(name))]
+	return command, ok
+}
+
+// buildNameTable registers the names and aliases of the configured commands.
+// defined holds the file each command comes from. Every name or alias that collides
+// with another one is reported, naming both owners.
+func buildNameTable(commands map[string]Command, defined map[string]layer, caseInsensitive bool) (*nameTable, ValidationErrors) {
+	table := newNameTable(caseInsensitive)
+	var errs ValidationErrors
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Command names first, so that an alias never hides a command
+	for _, name := range names {
+		owner := fmt.Sprintf("command '%s' in %s", name, defined[name].path)
+		if existing, ok := table.add(name, name, owner); !ok {
+			errs = append(errs, nameError(defined[name], name, nil, "command name collides with "+existing))
+		}
+	}
+
+	for _, name := range names {
+		for i, alias := range commands[name].Aliases {
+			if table.key(alias) == table.key(name) {
+				// Redundant, but harmless
+				continue
+			}
+			owner := fmt.Sprintf("alias '%s' of command '%s' in %s", alias, name, defined[name].path)
+			if existing, ok := table.add(alias, name, owner); !ok {
+				path := []string{"commands", name, "aliases", strconv.Itoa(i)}
+				errs = append(errs, nameError(defined[name], name, path, fmt.Sprintf("alias '%s' collides with %s", alias, existing)))
+			}
+		}
+	}
+	return table, errs
+}
+
+// nameError creates a collision error positioned in the file defining command
+func nameError(l layer, command string, path []string, message string) *ValidationError {
+	e := &ValidationError{File: l.path, Command: command, Message: message, path: path}
+	if path != nil {
+		e.Field = "aliases"
+	}
+	e.Line, e.Column = l.positions.lookup(fieldPath(e)...)
+	return e
+}
+
+// validateAliases checks that every alias of a command can be typed
+func validateAliases(name string, cmd *Command) ValidationErrors {
+	var errs ValidationErrors
+	for i, alias := range cmd.Aliases {
+		if alias == "" || strings.IndexFunc(alias, unicode.IsSpace) >= 0 {
+			errs = append(errs, &ValidationError{
+				Command: name,
+				Field:   "aliases",
+				Message: fmt.Sprintf("invalid alias %q: must be non-empty and contain no whitespace", alias),
+				path:    []string{"commands", name, "aliases", strconv.Itoa(i)},
+			})
+		}
+	}
+	return errs
+}
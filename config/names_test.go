@@ -0,0 +1,126 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestGetCommandByAlias tests lookup by alias, with whitespace and optionally case ignored
+func TestGetCommandByAlias(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"commands": {
+  "chrome": {"path": "/bin/chrome", "aliases": ["ch", "browser"]},
+  "Code": {"path": "/bin/code"}
+}}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	for _, name := range []string{"chrome", "ch", "browser", " chrome ", "ch\t"} {
+		if cmd, ok := cm.GetCommand(name); !ok || cmd.Path != "/bin/chrome" {
+			t.Errorf("Expected %q to find chrome, got %+v, %v", name, cmd, ok)
+		}
+		if canonical, _ := cm.CommandName(name); canonical != "chrome" {
+			t.Errorf("Expected %q to resolve to chrome, got %q", name, canonical)
+		}
+	}
+	for _, name := range []string{"Chrome", "CH", "code"} {
+		if _, ok := cm.GetCommand(name); ok {
+			t.Errorf("Expected %q not to match with case-sensitive lookup", name)
+		}
+	}
+	if names := cm.CommandNames(); len(names) != 2 {
+		t.Errorf("Expected aliases to stay out of the command names, got %v", names)
+	}
+
+	writeConfig(t, configFile, `{"case_insensitive": true, "commands": {
+  "chrome": {"path": "/bin/chrome", "aliases": ["ch"]},
+  "Code": {"path": "/bin/code"}
+}}`)
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to reload configuration: %v", err)
+	}
+	for name, expected := range map[string]string{"Chrome": "chrome", "CH": "chrome", "code": "Code", " CODE ": "Code"} {
+		if canonical, ok := cm.CommandName(name); !ok || canonical != expected {
+			t.Errorf("Expected %q to resolve to %s, got %q", name, expected, canonical)
+		}
+	}
+}
+
+// TestAliasCollisions tests that colliding names are rejected naming both owners
+func TestAliasCollisions(t *testing.T) {
+	dir := t.TempDir()
+	team := filepath.Join(dir, "team.json")
+	writeConfig(t, team, `{"commands": {"chat": {"path": "/bin/chat", "aliases": ["talk"]}}}`)
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig(t, configFile, `{
+  "include": ["team.json"],
+  "commands": {
+    "chrome": {"path": "/bin/chrome", "aliases": ["chat", "chrome"]},
+    "slack": {"path": "/bin/slack", "aliases": ["talk"]}
+  }
+}`)
+
+	_, err := loadConfig(t, configFile)
+	errs := AsValidationErrors(err)
+	if len(errs) != 2 {
+		t.Fatalf("Expected two collisions, got: %v", errs)
+	}
+
+	first := errs[0]
+	if first.File != configFile || first.Command != "chrome" || first.Field != "aliases" || first.Line != 4 || first.Column != 51 {
+		t.Errorf("Expected the alias chat of chrome to be reported at its position, got: %v", first)
+	}
+	if !strings.Contains(first.Message, "alias 'chat' collides with command 'chat' in "+team) {
+		t.Errorf("Expected both owners to be named, got: %s", first.Message)
+	}
+	if second := errs[1]; second.Command != "slack" || !strings.Contains(second.Message, "alias 'talk' of command 'chat' in "+team) {
+		t.Errorf("Expected the alias talk of slack to collide with chat's alias, got: %v", second)
+	}
+}
+
+// TestCaseInsensitiveCollisions tests that names differing only in case are rejected
+// when case is ignored
+func TestCaseInsensitiveCollisions(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"commands": {
+  "Chrome": {"path": "/bin/chrome"},
+  "chrome": {"path": "/bin/chromium"},
+  "edit": {"path": "/bin/edit", "aliases": ["CHROME"]}
+}}`)
+	if _, err := loadConfig(t, configFile); err != nil {
+		t.Fatalf("Expected names differing in case to be allowed by default: %v", err)
+	}
+
+	writeConfig(t, configFile, `{"case_insensitive": true, "commands": {
+  "Chrome": {"path": "/bin/chrome"},
+  "chrome": {"path": "/bin/chromium"},
+  "edit": {"path": "/bin/edit", "aliases": ["CHROME"]}
+}}`)
+	_, err := loadConfig(t, configFile)
+	errs := AsValidationErrors(err)
+	if len(errs) != 2 {
+		t.Fatalf("Expected two collisions, got: %v", errs)
+	}
+	if errs[0].Command != "chrome" || !strings.Contains(errs[0].Message, "command name collides with command 'Chrome'") {
+		t.Errorf("Unexpected error: %v", errs[0])
+	}
+	if errs[1].Command != "edit" || !strings.Contains(errs[1].Message, "alias 'CHROME' collides with command 'Chrome'") {
+		t.Errorf("Unexpected error: %v", errs[1])
+	}
+}
+
+// TestInvalidAliases tests that aliases which cannot be typed are rejected
+func TestInvalidAliases(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{"commands": {"chrome": {"path": "/bin/chrome", "aliases": ["", "web browser"]}}}`)
+
+	_, err := loadConfig(t, configFile)
+	errs := AsValidationErrors(err)
+	if len(errs) != 2 || errs[0].Field != "aliases" || !strings.Contains(errs[1].Message, `"web browser"`) {
+		t.Errorf("Expected both aliases to be rejected, got: %v", errs)
+	}
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// Output modes: where the captured output of a command goes
+const (
+	OutputShow      = "show"      // The launcher window
+	OutputClipboard = "clipboard" // The clipboard
+	OutputNotify    = "notify"    // A desktop notification
+)
+
+// validateOutput checks that a timeout is a positive duration and only comes with an output mode
+func validateOutput(name string, cmd *Command) ValidationErrors {
+	if cmd.Timeout == "" {
+		return nil
+	}
+	if cmd.Output == "" {
+		return ValidationErrors{{Command: name, Field: "timeout", Message: "timeout requires an output mode"}}
+	}
+	if timeout, err := time.ParseDuration(cmd.Timeout); err != nil || timeout <= 0 {
+		return ValidationErrors{{Command: name, Field: "timeout", Message: fmt.Sprintf("invalid timeout %q, expected a duration such as \"5s\"", cmd.Timeout)}}
+	}
+	return nil
+}
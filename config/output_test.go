@@ -0,0 +1,56 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLoadOutputModes tests that output modes load and that timeouts are checked
+func TestLoadOutputModes(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, configFile, `[commands.uuid]
+path = "/usr/bin/uuidgen"
+output = "clipboard"
+
+[commands.status]
+shell = "git -C ~/repo status -s"
+output = "show"
+timeout = "30s"
+`)
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("status"); cmd.Output != OutputShow || cmd.Timeout != "30s" {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+
+	writeConfig(t, configFile, `[commands.a]
+path = "/bin/a"
+timeout = "5s"
+
+[commands.b]
+path = "/bin/b"
+output = "notify"
+timeout = "-1s"
+
+[commands.c]
+output = "show"
+steps = [{path = "/bin/c"}]
+`)
+	expected := []string{
+		"command 'a' field 'timeout': timeout requires an output mode",
+		`command 'b' field 'timeout': invalid timeout "-1s", expected a duration such as "5s"`,
+		"command 'c' field 'output': output cannot be combined with steps",
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+
+	writeConfig(t, configFile, "[commands.a]\npath = \"/bin/a\"\noutput = \"print\"\n")
+	if err := cm.Load(); err == nil || !strings.Contains(err.Error(), "expected one of 'show', 'clipboard', 'notify'") {
+		t.Errorf("Expected an unknown output mode to be rejected, got: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import "runtime"
+
+// currentOS is the platform whose variants are applied; tests replace it
+var currentOS = runtime.GOOS
+
+// PlatformOverride holds the fields of a command that differ on one operating system.
+//
+// Example JSON:
+//
+//	{
+//	  "commands": {
+//	    "vscode": {
+//	      "args": ["-n"],
+//	      "windows": {"path": "C:\\Program Files\\Microsoft VS Code\\Code.exe"},
+//	      "linux": {"path": "/usr/bin/code"},
+//	      "darwin": {"path": "/usr/local/bin/code"}
+//	    }
+//	  }
+//	}
+//
+// The fields of the command itself are shared defaults. A non-empty Path, Shell or Cwd
+// and a present Args or Interpreter replace the defaults; Env is merged over them. A
+// variant setting Path replaces a shell command and its interpreter, and one setting
+// Shell replaces Path and Args, so a command can be a shell command on one platform
+// only. An empty variant ({}) keeps the defaults as they are but still makes the
+// command available on that platform.
+//
+// Args is a pointer so that an explicit empty list ("args": []), which clears the
+// default arguments, is told apart from no list and survives conversion between formats.
+type PlatformOverride struct {
+	Path        string            `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty"`                      // Executable on this platform
+	Args        *[]string         `json:"args,omitempty" toml:"args,omitempty" yaml:"args,omitempty"`                      // Arguments on this platform; nil keeps the defaults
+	Shell       string            `json:"shell,omitempty" toml:"shell,omitempty" yaml:"shell,omitempty"`                   // Shell command on this platform
+	Interpreter []string          `json:"interpreter,omitempty" toml:"interpreter,omitempty" yaml:"interpreter,omitempty"` // Shell that runs it on this platform
+	Cwd         string            `json:"cwd,omitempty" toml:"cwd,omitempty" yaml:"cwd,omitempty"`                         // Working directory on this platform
+	Env         map[string]string `json:"env,omitempty" toml:"env,omitempty" yaml:"env,omitempty"`                         // Extra environment variables on this platform
+}
+
+// variant returns the override of cmd for goos
+func (cmd *Command) variant(goos string) *PlatformOverride {
+	switch goos {
+	case "linux":
+		return cmd.Linux
+	case "windows":
+		return cmd.Windows
+	case "darwin":
+		return cmd.Darwin
+	}
+	return nil
+}
+
+// hasVariants reports whether cmd has any platform variant
+func (cmd *Command) hasVariants() bool {
+	return cmd.Linux != nil || cmd.Windows != nil || cmd.Darwin != nil
+}
+
+// applyPlatform applies the variant for goos to cmd and removes all variants, so the
+// result describes what runs on this platform. It returns false if cmd has variants
+// but none for goos, in which case the command is not available here.
+func applyPlatform(cmd *Command, goos string) bool {
+	if !cmd.hasVariants() {
+		return true
+	}
+	override := cmd.variant(goos)
+	cmd.Linux, cmd.Windows, cmd.Darwin = nil, nil, nil
+	if override == nil {
+		return false
+	}
+
+	if override.Path != "" {
+		cmd.Path = override.Path
+		cmd.Shell, cmd.Interpreter = "", nil
+	}
+	if override.Shell != "" {
+		cmd.Shell = override.Shell
+		cmd.Path, cmd.Args = "", nil
+	}
+	if override.Args != nil {
+		cmd.Args = *override.Args
+	}
+	if override.Interpreter != nil {
+		cmd.Interpreter = override.Interpreter
+	}
+	if override.Cwd != "" {
+		cmd.Cwd = override.Cwd
+	}
+	if len(override.Env) > 0 {
+		env := make(map[string]string, len(cmd.Env)+len(override.Env))
+		for key, value := range cmd.Env {
+			env[key] = value
+		}
+		for key, value := range override.Env {
+			env[key] = value
+		}
+		cmd.Env = env
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// setOS makes Load apply the variants of goos for the rest of the test
+func setOS(t *testing.T, goos string) {
+	t.Helper()
+	previous := currentOS
+	currentOS = goos
+	t.Cleanup(func() { currentOS = previous })
+}
+
+// TestApplyPlatform tests how a variant overrides the shared fields
+func TestApplyPlatform(t *testing.T) {
+	base := func() Command {
+		return Command{
+			Path:  "/usr/bin/code",
+			Args:  []string{"-n"},
+			Cwd:   "/work",
+			Env:   map[string]string{"A": "1", "B": "2"},
+			Linux: &PlatformOverride{},
+			Windows: &PlatformOverride{
+				Path: `C:\Code\Code.exe`,
+				Args: &[]string{},
+				Env:  map[string]string{"B": "win", "C": "3"},
+			},
+		}
+	}
+
+	cmd := base()
+	if !applyPlatform(&cmd, "linux") {
+		t.Fatal("Expected an empty variant to make the command available")
+	}
+	expected := Command{Path: "/usr/bin/code", Args: []string{"-n"}, Cwd: "/work", Env: map[string]string{"A": "1", "B": "2"}}
+	if !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("Expected the shared fields unchanged, got %+v", cmd)
+	}
+
+	cmd = base()
+	applyPlatform(&cmd, "windows")
+	expected = Command{Path: `C:\Code\Code.exe`, Args: []string{}, Cwd: "/work", Env: map[string]string{"A": "1", "B": "win", "C": "3"}}
+	if !reflect.DeepEqual(cmd, expected) {
+		t.Errorf("Expected the Windows variant to apply, got %+v", cmd)
+	}
+
+	cmd = base()
+	if applyPlatform(&cmd, "darwin") {
+		t.Error("Expected the command to be unavailable without a darwin variant")
+	}
+
+	cmd = Command{Path: "/bin/true"}
+	if !applyPlatform(&cmd, "plan9") {
+		t.Error("Expected commands without variants to be available everywhere")
+	}
+}
+
+// TestLoadPlatformVariants tests that commands without a variant for this platform are hidden
+func TestLoadPlatformVariants(t *testing.T) {
+	setOS(t, "linux")
+
+	dir := t.TempDir()
+	writeConfig(t, filepath.Join(dir, "shared.json"), `{"commands": {"paint": {"path": "/usr/bin/gimp"}}}`)
+	configFile := filepath.Join(dir, "config.json")
+	writeConfig(t, configFile, `{"include": ["shared.json"], "commands": {
+  "vscode": {"args": ["-n"], "windows": {"path": "C:\\Code\\Code.exe"}, "linux": {"path": "/usr/bin/code"}},
+  "notepad": {"windows": {"path": "C:\\Windows\\notepad.exe"}},
+  "paint": {"windows": {"path": "C:\\Windows\\mspaint.exe"}},
+  "shell": {"path": "/bin/sh"}
+}}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	if names := cm.AllCommandNames(); !reflect.DeepEqual(names, []string{"shell", "vscode"}) {
+		t.Errorf("Expected only commands available on linux, got %v", names)
+	}
+	if _, exists := cm.GetCommand("notepad"); exists {
+		t.Error("Expected a Windows-only command to be unavailable")
+	}
+	cmd, _ := cm.GetCommand("vscode")
+	if cmd.Path != "/usr/bin/code" || !reflect.DeepEqual(cmd.Args, []string{"-n"}) || cmd.Windows != nil {
+		t.Errorf("Expected the linux variant with the shared args, got %+v", cmd)
+	}
+
+	writeConfig(t, configFile, `{"commands": {"vscode": {"args": ["-n"], "linux": {}}}}`)
+	errs := AsValidationErrors(cm.Load())
+	if len(errs) != 1 || errs[0].Command != "vscode" || errs[0].Field != "path" {
+		t.Errorf("Expected a missing path on this platform to be reported, got: %v", errs)
+	}
+}
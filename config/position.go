@@ -0,0 +1,191 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// position is a 1-based line and column in a configuration file
+type position struct {
+	line, column int
+}
+
+// positionIndex maps locations in a configuration document to where they are defined.
+//
+// A location is the path of object keys and array indices leading to a value, e.g.
+// ("commands", "chrome", "path"). Positions point at the key for object members and at
+// the value for array elements, which is what an editor should jump to.
+//
+// JSON objects may repeat a key, in which case encoding/json silently keeps the last
+// value. The index records every definition of such keys so they can be reported.
+type positionIndex struct {
+	locations  map[string]position   // Position of the last definition of each location
+	duplicates map[string][]position // Every definition of locations defined more than once
+}
+
+// newPositionIndex creates an empty index. Lookups in an empty index report no
+// position, which is what formats without position information use.
+func newPositionIndex() *positionIndex {
+	return &positionIndex{
+		locations:  make(map[string]position),
+		duplicates: make(map[string][]position),
+	}
+}
+
+// record stores the position of an object member, remembering earlier definitions of the same key
+func (p *positionIndex) record(key string, pos position) {
+	if previous, ok := p.locations[key]; ok {
+		if len(p.duplicates[key]) == 0 {
+			p.duplicates[key] = []position{previous}
+		}
+		p.duplicates[key] = append(p.duplicates[key], pos)
+	}
+	p.locations[key] = pos
+}
+
+// definitions returns every definition of a location defined more than once,
+// in file order, or nil if it is defined at most once
+func (p *positionIndex) definitions(path ...string) []position {
+	return p.duplicates[pathKey(path...)]
+}
+
+// lookup returns the 1-based line and column of the deepest known location along path.
+// For a missing field this is the enclosing command, so the user still lands close by.
+func (p *positionIndex) lookup(path ...string) (line, column int) {
+	for n := len(path); n >= 0; n-- {
+		if pos, ok := p.locations[pathKey(path[:n]...)]; ok {
+			return pos.line, pos.column
+		}
+	}
+	return 0, 0
+}
+
+// jsonIndexer walks the tokens of a JSON document. Tokens are visited in file order,
+// so line numbers are counted incrementally from the previous token.
+type jsonIndexer struct {
+	index     *positionIndex
+	data      []byte
+	offset    int // Offset up to which lines have been counted
+	line      int // Line at offset
+	lineStart int // Offset of the first byte of line
+}
+
+// indexJSON records the position of every value in a JSON document.
+// Malformed documents are indexed up to the first syntax error.
+func indexJSON(data []byte) *positionIndex {
+	j := &jsonIndexer{index: newPositionIndex(), data: data, line: 1}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	j.index.locations[""] = j.position(j.tokenStart(0))
+	_ = j.indexValue(decoder, nil)
+	return j.index
+}
+
+// indexValue reads one value from decoder and records the positions of its members
+func (j *jsonIndexer) indexValue(decoder *json.Decoder, path []string) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		for decoder.More() {
+			start := j.tokenStart(decoder.InputOffset())
+			key, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			child := append(path[:len(path):len(path)], key.(string))
+			j.index.record(pathKey(child...), j.position(start))
+			if err := j.indexValue(decoder, child); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	case json.Delim('['):
+		for i := 0; decoder.More(); i++ {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			j.index.locations[pathKey(child...)] = j.position(j.tokenStart(decoder.InputOffset()))
+			if err := j.indexValue(decoder, child); err != nil {
+				return err
+			}
+		}
+		_, err = decoder.Token()
+	}
+	return err
+}
+
+// tokenStart skips the whitespace and separators the decoder has not consumed yet,
+// returning the offset of the next token
+func (j *jsonIndexer) tokenStart(offset int64) int {
+	i := int(offset)
+	for i < len(j.data) && strings.IndexByte(" \t\r\n,:", j.data[i]) >= 0 {
+		i++
+	}
+	return i
+}
+
+// position converts an offset at or after the previous one to a line and column.
+// Columns count characters rather than bytes.
+func (j *jsonIndexer) position(offset int) position {
+	if offset > len(j.data) {
+		offset = len(j.data)
+	}
+	for i := j.offset; i < offset; i++ {
+		if j.data[i] == '\n' {
+			j.line++
+			j.lineStart = i + 1
+		}
+	}
+	j.offset = offset
+	return position{j.line, utf8.RuneCount(j.data[j.lineStart:offset]) + 1}
+}
+
+// offsetPosition converts a byte offset in data to a 1-based line and column
+func offsetPosition(data []byte, offset int) position {
+	j := &jsonIndexer{data: data, line: 1}
+	return j.position(offset)
+}
+
+// indexYAML records the position of every value in a parsed YAML document
+func indexYAML(root *yaml.Node) *positionIndex {
+	index := newPositionIndex()
+	node := root
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	index.locations[""] = position{node.Line, node.Column}
+	indexYAMLNode(index, node, nil)
+	return index
+}
+
+// indexYAMLNode records the positions of the members of a mapping or sequence node
+func indexYAMLNode(index *positionIndex, node *yaml.Node, path []string) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			child := append(path[:len(path):len(path)], key.Value)
+			index.record(pathKey(child...), position{key.Line, key.Column})
+			indexYAMLNode(index, value, child)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			child := append(path[:len(path):len(path)], strconv.Itoa(i))
+			index.locations[pathKey(child...)] = position{item.Line, item.Column}
+			indexYAMLNode(index, item, child)
+		}
+	}
+}
+
+// pathKey joins a location into a map key. NUL is used as the separator because,
+// unlike "." or "/", it does not occur in real command names.
+func pathKey(path ...string) string {
+	return strings.Join(path, "\x00")
+}
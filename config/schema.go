@@ -0,0 +1,418 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaNode is a JSON Schema (draft 2020-12) restricted to the keywords the
+// configuration needs. The same tree is printed for editors and used by Load to
+// check documents, so the two cannot disagree.
+type schemaNode struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Properties           map[string]*schemaNode `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AnyOf                []*schemaNode          `json:"anyOf,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"` // false or a *schemaNode
+	Items                *schemaNode            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+}
+
+// schemaDescriptions documents every field of the configuration types, keyed by
+// "Type.Field". A test makes sure that every field is described.
+var schemaDescriptions = map[string]string{
+	"Config.Include":         "Further configuration files or glob patterns to load, relative to this file.",
+	"Config.Commands":        "Commands by the name typed in the launcher.",
+	"Config.AllowDuplicates": "Keep the last definition of a command defined more than once instead of failing.",
+	"Config.Desktop":         "Import installed applications from freedesktop.org .desktop files.",
+	"Config.PathCommands":    "Offer every executable in the directories of $PATH as a command.",
+	"Config.CaseInsensitive": "Ignore case when looking up command names and aliases.",
+	"Config.StrictEnv":       "Set strict_env on every command defined in this file.",
+	"Config.Lenient":         "Report unknown fields in this file as warnings instead of errors.",
+	"Config.Interpreter":     "Program and flags that run the shell commands of this file, e.g. [\"pwsh\", \"-Command\"].",
+
+	"Command.Path":           "Executable to run. May refer to environment variables and placeholders.",
+	"Command.Args":           "Command-line arguments. May refer to environment variables and placeholders.",
+	"Command.Shell":          "Command line run by a shell instead of path and args. Placeholders are quoted for the shell.",
+	"Command.Interpreter":    "Program and flags that run shell, e.g. [\"bash\", \"-c\"]. Defaults to /bin/sh -c, or cmd.exe /C on Windows.",
+	"Command.AllowArgs":      "Append arguments typed after the command name.",
+	"Command.Cwd":            "Working directory, relative to the configuration file.",
+	"Command.Env":            "Environment variables to set, merged over the launcher's environment.",
+	"Command.EnvClear":       "Start from an empty environment so that only env is set.",
+	"Command.StrictEnv":      "Fail the launch when path, args or cwd refer to an unset environment variable.",
+	"Command.Aliases":        "Further names the command can be launched by.",
+	"Command.Description":    "Short text shown next to the command.",
+	"Command.Tags":           "Keywords to find the command by as #tag.",
+	"Command.Icon":           "Image file shown next to the command, relative to the configuration file.",
+	"Command.Hidden":         "Leave the command out of listings; it still runs by its exact name.",
+	"Command.SingleInstance": "What launching the command does while a process started for it is still running.",
+	"Command.Activate":       "Command run instead of a second instance; its path and args may use {pid}.",
+	"Command.Output":         "Capture the output and show it in the launcher, copy it to the clipboard or send it as a notification.",
+	"Command.Timeout":        "How long to wait for a command with output before killing it, e.g. \"30s\". Defaults to 10s.",
+	"Command.Steps":          "Makes the command a macro that runs these steps in order.",
+	"Command.Linux":          "Overrides on Linux.",
+	"Command.Windows":        "Overrides on Windows.",
+	"Command.Darwin":         "Overrides on macOS.",
+
+	"PlatformOverride.Path":        "Executable on this platform.",
+	"PlatformOverride.Args":        "Arguments on this platform.",
+	"PlatformOverride.Shell":       "Shell command on this platform, replacing path and args.",
+	"PlatformOverride.Interpreter": "Program and flags that run shell on this platform.",
+	"PlatformOverride.Cwd":         "Working directory on this platform.",
+	"PlatformOverride.Env":         "Extra environment variables on this platform, merged over env.",
+
+	"Step.Command": "Configured command or alias to run.",
+	"Step.Path":    "Executable to run, using the cwd and env of the macro.",
+	"Step.Shell":   "Command line run by the interpreter of the macro.",
+	"Step.Args":    "Arguments of path, or the arguments passed to command as if typed.",
+	"Step.Wait":    "Run the next step once this one has started, or once its process has exited.",
+	"Step.Delay":   "Pause before the step, e.g. \"2s\".",
+	"Step.OnError": "Skip the remaining steps when this one fails, or continue.",
+
+	"DesktopImport.Enabled":  "Import .desktop entries.",
+	"DesktopImport.Dirs":     "Applications directories to scan, earlier ones shadowing later ones.",
+	"DesktopImport.Terminal": "Program and arguments that run entries with Terminal=true.",
+}
+
+// schemaRequired lists the fields a type requires. If several are listed, any one of
+// them will do: a command needs a path, a shell command or steps, unless a platform
+// variant provides the path.
+var schemaRequired = map[reflect.Type][]string{
+	reflect.TypeOf(Command{}):       {"path", "shell", "steps", "linux", "windows", "darwin"},
+	reflect.TypeOf(Step{}):          {"command", "path", "shell"},
+	reflect.TypeOf(DesktopImport{}): {"enabled"},
+}
+
+// schemaEnums lists the values allowed for string fields, keyed like schemaDescriptions
+var schemaEnums = map[string][]string{
+	"Command.SingleInstance": {SingleInstanceIgnore, SingleInstanceNotify, SingleInstanceActivate},
+	"Command.Output":         {OutputShow, OutputClipboard, OutputNotify},
+	"Step.Wait":              {WaitStart, WaitExit},
+	"Step.OnError":           {OnErrorAbort, OnErrorContinue},
+}
+
+// configSchema is the schema of a configuration file, generated from the Config type
+var configSchema = newConfigSchema()
+
+// Schema returns the JSON Schema of configuration files, indented for reading.
+// Editors can use it to complete and check config.json.
+func Schema() ([]byte, error) {
+	data, err := json.MarshalIndent(configSchema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// newConfigSchema generates the schema of the Config type
+func newConfigSchema() *schemaNode {
+	root := schemaFor(reflect.TypeOf(Config{}), "")
+	root.Schema = "https://json-schema.org/draft/2020-12/schema"
+	root.Title = "Application launcher configuration"
+	// Lets editors find the schema; the launcher itself ignores it
+	root.Properties["$schema"] = &schemaNode{Type: "string", Description: "JSON Schema of this file."}
+	return root
+}
+
+// schemaFor generates the schema of a Go type as encoding/json sees it
+func schemaFor(t reflect.Type, description string) *schemaNode {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), description)
+	case reflect.String:
+		return &schemaNode{Type: "string", Description: description}
+	case reflect.Bool:
+		return &schemaNode{Type: "boolean", Description: description}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schemaNode{Type: "integer", Description: description}
+	case reflect.Float32, reflect.Float64:
+		return &schemaNode{Type: "number", Description: description}
+	case reflect.Slice:
+		return &schemaNode{Type: "array", Description: description, Items: schemaFor(t.Elem(), "")}
+	case reflect.Map:
+		return &schemaNode{Type: "object", Description: description, AdditionalProperties: schemaFor(t.Elem(), "")}
+	case reflect.Struct:
+		return structSchema(t, description)
+	}
+	panic(fmt.Sprintf("config: no schema for type %s", t))
+}
+
+// structSchema generates the schema of a struct type. Fields are named by their json
+// tag, and fields that are not in the struct are not allowed.
+func structSchema(t reflect.Type, description string) *schemaNode {
+	node := &schemaNode{
+		Type:                 "object",
+		Description:          description,
+		Properties:           make(map[string]*schemaNode),
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		key := t.Name() + "." + field.Name
+		node.Properties[name] = schemaFor(field.Type, schemaDescriptions[key])
+		node.Properties[name].Enum = schemaEnums[key]
+	}
+
+	switch required := schemaRequired[t]; {
+	case len(required) == 1:
+		node.Required = required
+	case len(required) > 1:
+		for _, name := range required {
+			node.AnyOf = append(node.AnyOf, &schemaNode{Required: []string{name}})
+		}
+	}
+	return node
+}
+
+// jsonName returns the name of a struct field in JSON documents, or "" if the field
+// is not encoded
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// schemaProblem is a place where a document does not match the schema
+type schemaProblem struct {
+	path    []string // Location of the offending value
+	message string
+	unknown bool // The problem is an unknown field, which lenient files accept
+}
+
+// checkSchema checks a decoded document against node and returns every problem.
+// Null values count as absent, as the decoders treat them.
+func checkSchema(node *schemaNode, value any, path []string) []schemaProblem {
+	if value == nil {
+		return nil
+	}
+	if kind := valueKind(value); !kindMatches(node.Type, kind, value) {
+		return []schemaProblem{{path: path, message: fmt.Sprintf("expected %s, got %s", node.Type, kind)}}
+	}
+
+	var problems []schemaProblem
+	switch node.Type {
+	case "string":
+		if len(node.Enum) > 0 && !slices.Contains(node.Enum, value.(string)) {
+			problems = append(problems, schemaProblem{path: path, message: fmt.Sprintf("invalid value %q, expected one of %s", value, quoteList(node.Enum))})
+		}
+
+	case "array":
+		for i, item := range asArray(value) {
+			problems = append(problems, checkSchema(node.Items, item, appendPath(path, strconv.Itoa(i)))...)
+		}
+
+	case "object":
+		members := asObject(value)
+		keys := make([]string, 0, len(members))
+		for key := range members {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := node.Properties[key]
+			if child == nil {
+				child, _ = node.AdditionalProperties.(*schemaNode)
+			}
+			if child == nil {
+				problems = append(problems, schemaProblem{
+					path:    appendPath(path, key),
+					message: unknownFieldMessage(key, node),
+					unknown: true,
+				})
+				continue
+			}
+			problems = append(problems, checkSchema(child, members[key], appendPath(path, key))...)
+		}
+		problems = append(problems, checkRequired(node, members, path)...)
+	}
+	return problems
+}
+
+// checkRequired reports the required fields missing from an object
+func checkRequired(node *schemaNode, members map[string]any, path []string) []schemaProblem {
+	var problems []schemaProblem
+	for _, name := range node.Required {
+		if members[name] == nil {
+			problems = append(problems, schemaProblem{path: path, message: fmt.Sprintf("missing required field '%s'", name)})
+		}
+	}
+
+	if len(node.AnyOf) == 0 {
+		return problems
+	}
+	var names []string
+	for _, alternative := range node.AnyOf {
+		for _, name := range alternative.Required {
+			if members[name] != nil {
+				return problems
+			}
+			names = append(names, "'"+name+"'")
+		}
+	}
+	message := fmt.Sprintf("missing required field %s", names[0])
+	if len(names) > 1 {
+		message += " (or one of " + strings.Join(names[1:], ", ") + ")"
+	}
+	return append(problems, schemaProblem{path: path, message: message})
+}
+
+// unknownFieldMessage describes an unknown field, suggesting a known one that is
+// spelled similarly
+func unknownFieldMessage(key string, node *schemaNode) string {
+	message := fmt.Sprintf("unknown field '%s'", key)
+	best, bestDistance := "", 3
+	for name := range node.Properties {
+		if distance := editDistance(strings.ToLower(key), name); distance < bestDistance || distance == bestDistance && name < best {
+			best, bestDistance = name, distance
+		}
+	}
+	if best != "" {
+		message += fmt.Sprintf(", did you mean '%s'?", best)
+	}
+	return message
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// valueKind names the JSON type of a value decoded from JSON, YAML or TOML
+func valueKind(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64, uint64:
+		return "number"
+	case []any, []map[string]any:
+		return "array"
+	case map[string]any, map[any]any:
+		return "object"
+	case time.Time:
+		return "date"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// kindMatches reports whether a value of the given kind has the schema type want
+func kindMatches(want, kind string, value any) bool {
+	switch want {
+	case "integer":
+		if f, ok := value.(float64); ok {
+			return f == float64(int64(f))
+		}
+		return kind == "number"
+	case "number":
+		return kind == "number"
+	}
+	return want == kind
+}
+
+// asArray returns the elements of an array value
+func asArray(value any) []any {
+	if tables, ok := value.([]map[string]any); ok {
+		// TOML arrays of tables
+		items := make([]any, len(tables))
+		for i, table := range tables {
+			items[i] = table
+		}
+		return items
+	}
+	return value.([]any)
+}
+
+// asObject returns the members of an object value
+func asObject(value any) map[string]any {
+	if members, ok := value.(map[any]any); ok {
+		// YAML mappings with keys that are not all strings
+		converted := make(map[string]any, len(members))
+		for key, member := range members {
+			converted[fmt.Sprint(key)] = member
+		}
+		return converted
+	}
+	return value.(map[string]any)
+}
+
+// quoteList formats values as a quoted, comma-separated list
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = "'" + value + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// appendPath returns path extended by key without modifying path
+func appendPath(path []string, key string) []string {
+	return append(path[:len(path):len(path)], key)
+}
+
+// schemaErrors converts schema problems into validation errors positioned in the
+// document. Unknown fields are returned separately as warnings if lenient is set.
+func schemaErrors(problems []schemaProblem, positions *positionIndex, lenient bool) (errs, warnings ValidationErrors) {
+	for _, problem := range problems {
+		e := &ValidationError{Message: problem.message, path: problem.path}
+		switch {
+		case len(problem.path) == 0:
+			// The document is not an object at all
+			e.Message = "failed to parse config file: " + problem.message
+		case len(problem.path) >= 2 && problem.path[0] == "commands":
+			e.Command = problem.path[1]
+			if len(problem.path) >= 4 || len(problem.path) == 3 && !problem.unknown {
+				e.Field = problem.path[2]
+			}
+		case len(problem.path) >= 2 || len(problem.path) == 1 && !problem.unknown:
+			e.Field = problem.path[0]
+		}
+		e.Line, e.Column = positions.lookup(problem.path...)
+
+		if problem.unknown && lenient {
+			warnings = append(warnings, e)
+		} else {
+			errs = append(errs, e)
+		}
+	}
+	return errs, warnings
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var updateSchema = flag.Bool("update", false, "Rewrite config.schema.json from the Go types")
+
+// schemaFile is the schema shipped for editors
+var schemaFile = filepath.Join("..", "config.schema.json")
+
+// TestSchemaFileUpToDate tests that the shipped schema matches the Go types.
+// Run "go test ./config -run TestSchemaFileUpToDate -update" after changing them.
+func TestSchemaFileUpToDate(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+	if *updateSchema {
+		if err := os.WriteFile(schemaFile, schema, 0644); err != nil {
+			t.Fatalf("Failed to write schema: %v", err)
+		}
+	}
+
+	shipped, err := os.ReadFile(schemaFile)
+	if err != nil {
+		t.Fatalf("Failed to read schema: %v", err)
+	}
+	if !bytes.Equal(bytes.ReplaceAll(shipped, []byte("\r\n"), []byte("\n")), schema) {
+		t.Errorf("%s is out of date; regenerate it with -update", schemaFile)
+	}
+}
+
+// TestSchemaDescribesEveryField tests that every field of the configuration types
+// has a description and that no description is left over from a removed field
+func TestSchemaDescribesEveryField(t *testing.T) {
+	used := make(map[string]bool)
+	seen := make(map[reflect.Type]bool)
+	var walk func(reflect.Type)
+	walk = func(t reflect.Type) {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || seen[t] {
+			return
+		}
+		seen[t] = true
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if jsonName(field) == "" {
+				continue
+			}
+			key := t.Name() + "." + field.Name
+			used[key] = true
+			walk(field.Type)
+		}
+	}
+	walk(reflect.TypeOf(Config{}))
+
+	for key := range used {
+		if schemaDescriptions[key] == "" {
+			t.Errorf("Field %s has no schema description", key)
+		}
+	}
+	for key := range schemaDescriptions {
+		if !used[key] {
+			t.Errorf("Schema description %s does not belong to a field", key)
+		}
+	}
+	for typ := range schemaRequired {
+		if !seen[typ] {
+			t.Errorf("Required fields listed for %s, which is not part of the configuration", typ)
+		}
+	}
+}
+
+// TestSchemaAcceptsEncodedConfig tests that every field the Go types write is
+// accepted when the document is read back, in every format
+func TestSchemaAcceptsEncodedConfig(t *testing.T) {
+	override := &PlatformOverride{Path: "/usr/bin/code", Args: &[]string{"-n"}, Shell: "code -n", Interpreter: []string{"sh", "-c"}, Cwd: "/tmp", Env: map[string]string{"A": "1"}}
+	cfg := &Config{
+		Include:         []string{"conf.d/*.json"},
+		AllowDuplicates: true,
+		Desktop:         &DesktopImport{Enabled: true, Dirs: []string{"/usr/share/applications"}, Terminal: []string{"xterm", "-e"}},
+		PathCommands:    true,
+		CaseInsensitive: true,
+		StrictEnv:       true,
+		Lenient:         true,
+		Interpreter:     []string{"bash", "-c"},
+		Commands: map[string]Command{
+			"code": {
+				Path: "/usr/bin/code", Args: []string{"{query}"}, Shell: "code {query}", Interpreter: []string{"zsh", "-c"}, AllowArgs: true, Cwd: "/tmp",
+				Env: map[string]string{"B": "2"}, EnvClear: true, StrictEnv: true,
+				Aliases: []string{"c"}, Description: "Editor", Tags: []string{"dev"}, Icon: "/tmp/code.png", Hidden: true,
+				SingleInstance: SingleInstanceActivate, Activate: "focus", Output: OutputNotify, Timeout: "5s",
+				Steps: []Step{{Command: "c", Args: []string{"-n"}, Wait: WaitExit, Delay: "1s", OnError: OnErrorContinue}, {Path: "/bin/ls"}, {Shell: "ls"}},
+				Linux: override, Windows: override, Darwin: override,
+			},
+		},
+	}
+
+	for _, format := range []Format{FormatJSON, FormatTOML, FormatYAML} {
+		data, err := Encode(cfg, format)
+		if err != nil {
+			t.Fatalf("Failed to encode %s: %v", format, err)
+		}
+		decoded, _, warnings, err := decode(data, format)
+		if err != nil || len(warnings) > 0 {
+			t.Errorf("Expected encoded %s to match the schema, got %v %v", format, err, warnings)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, cfg) {
+			t.Errorf("Expected %s round trip to keep every field:\n got: %+v\nwant: %+v", format, decoded, cfg)
+		}
+	}
+}
+
+// TestLoadRejectsSchemaViolations tests that unknown fields, wrong types and missing
+// required fields are all reported with their positions
+func TestLoadRejectsSchemaViolations(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "comands": {},
+  "commands": {
+    "chrome": {"path": "/bin/chrome", "arg": ["--new-window"]},
+    "code": {"path": "/bin/code", "args": "-n", "hidden": "yes"},
+    "vim": {"args": [], "linux": {"pth": "/usr/bin/vim"}},
+    "tags": {"path": "/bin/app", "tags": ["dev", 1], "single_instance": "focus"},
+    "bare": {"args": []}
+  },
+  "desktop": {"dirs": []}
+}`)
+
+	cm, err := NewConfigManager(configFile)
+	if err != nil {
+		t.Fatalf("Failed to create ConfigManager: %v", err)
+	}
+	var got []string
+	for _, e := range AsValidationErrors(cm.Load()) {
+		got = append(got, strings.TrimPrefix(e.Error(), configFile+": "))
+	}
+	expected := []string{
+		"line 2, column 3: unknown field 'comands', did you mean 'commands'?",
+		"line 4, column 39: command 'chrome': unknown field 'arg', did you mean 'args'?",
+		"line 5, column 35: command 'code' field 'args': expected array, got string",
+		"line 5, column 49: command 'code' field 'hidden': expected boolean, got string",
+		"line 6, column 35: command 'vim' field 'linux': unknown field 'pth', did you mean 'path'?",
+		"line 7, column 50: command 'tags' field 'tags': expected string, got number",
+		"line 7, column 54: command 'tags' field 'single_instance': invalid value \"focus\", expected one of 'ignore', 'notify', 'activate'",
+		"line 8, column 5: command 'bare': missing required field 'path' (or one of 'shell', 'steps', 'linux', 'windows', 'darwin')",
+		"line 10, column 3: field 'desktop': missing required field 'enabled'",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+}
+
+// TestLoadLenientWarnsAboutUnknownFields tests that a lenient file loads despite
+// unknown fields, which are reported as warnings, while type errors still fail
+func TestLoadLenientWarnsAboutUnknownFields(t *testing.T) {
+	dir := t.TempDir()
+	mainFile := filepath.Join(dir, "config.yaml")
+	writeConfig(t, mainFile, "lenient: true\ninclude: [strict.toml]\ncommands:\n  chrome:\n    path: /bin/chrome\n    colour: blue\n")
+	writeConfig(t, filepath.Join(dir, "strict.toml"), "[commands.code]\npath = \"/bin/code\"\n")
+
+	cm, err := loadConfig(t, mainFile)
+	if err != nil {
+		t.Fatalf("Expected lenient file to load: %v", err)
+	}
+	warnings := cm.Warnings()
+	if len(warnings) != 1 || warnings[0].File != mainFile || warnings[0].Command != "chrome" || warnings[0].Line != 6 ||
+		!strings.Contains(warnings[0].Message, "unknown field 'colour'") {
+		t.Errorf("Expected one warning about 'colour', got: %v", warnings)
+	}
+
+	// Leniency applies to the file that asks for it only
+	writeConfig(t, filepath.Join(dir, "strict.toml"), "[commands.code]\npath = \"/bin/code\"\ncolour = \"red\"\n")
+	errs := AsValidationErrors(cm.Load())
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, "unknown field 'colour'") || !strings.HasSuffix(errs[0].File, "strict.toml") {
+		t.Errorf("Expected the included file to be strict, got: %v", errs)
+	}
+
+	writeConfig(t, mainFile, "lenient: true\ncommands:\n  chrome:\n    path: [/bin/chrome]\n")
+	if err := cm.Load(); err == nil || !strings.Contains(err.Error(), "expected string, got array") {
+		t.Errorf("Expected type errors to fail lenient files, got: %v", err)
+	}
+}
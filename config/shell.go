@@ -0,0 +1,29 @@
+package config
+
+// validateShell checks that a command uses either the path form or the shell form
+// and that an interpreter only comes with a shell command or a macro
+func validateShell(name string, cmd *Command) ValidationErrors {
+	if cmd.Shell == "" {
+		if cmd.Interpreter != nil && cmd.Steps == nil {
+			return ValidationErrors{{Command: name, Field: "interpreter", Message: "interpreter requires a shell command"}}
+		}
+		return nil
+	}
+
+	var errs ValidationErrors
+	if cmd.Path != "" {
+		errs = append(errs, &ValidationError{Command: name, Field: "shell", Message: "set either path or shell, not both"})
+	}
+	if len(cmd.Args) > 0 {
+		errs = append(errs, &ValidationError{Command: name, Field: "args", Message: "args cannot be combined with shell; write the arguments into the shell command"})
+	}
+	if cmd.Interpreter != nil && !validInterpreter(cmd.Interpreter) {
+		errs = append(errs, &ValidationError{Command: name, Field: "interpreter", Message: "interpreter must start with a program"})
+	}
+	return errs
+}
+
+// validInterpreter reports whether an interpreter names a program
+func validInterpreter(interpreter []string) bool {
+	return len(interpreter) > 0 && interpreter[0] != ""
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestLoadShellCommands tests that shell commands load, take the interpreter of their
+// file unless they set one, and that platform variants can switch between both forms
+func TestLoadShellCommands(t *testing.T) {
+	setOS(t, "linux")
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "interpreter": ["bash", "-c"],
+  "commands": {
+    "count": {"shell": "ls | wc -l"},
+    "ps": {"shell": "Get-Process", "interpreter": ["pwsh", "-Command"]},
+    "open": {"path": "/usr/bin/xdg-open", "args": ["."], "windows": {"shell": "start ."}},
+    "top": {"shell": "top | head", "linux": {"path": "/usr/bin/htop"}},
+    "procs": {"shell": "Get-Process", "interpreter": ["pwsh", "-Command"], "linux": {"path": "/usr/bin/htop"}, "windows": {}}
+  }
+}`)
+
+	cm, err := loadConfig(t, configFile)
+	if err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("count"); cmd.Shell != "ls | wc -l" || !reflect.DeepEqual(cmd.Interpreter, []string{"bash", "-c"}) {
+		t.Errorf("Expected the file's interpreter, got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("ps"); !reflect.DeepEqual(cmd.Interpreter, []string{"pwsh", "-Command"}) {
+		t.Errorf("Expected the command's own interpreter, got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("top"); cmd.Shell != "" || cmd.Path != "/usr/bin/htop" || cmd.Interpreter != nil {
+		t.Errorf("Expected the variant's path to replace the shell command, got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("procs"); cmd.Path != "/usr/bin/htop" || cmd.Interpreter != nil {
+		t.Errorf("Expected the variant's path to drop the shared interpreter, got %+v", cmd)
+	}
+
+	setOS(t, "windows")
+	if err := cm.Load(); err != nil {
+		t.Fatalf("Failed to load: %v", err)
+	}
+	if cmd, _ := cm.GetCommand("open"); cmd.Shell != "start ." || cmd.Path != "" || len(cmd.Args) != 0 {
+		t.Errorf("Expected the variant's shell command to replace path and args, got %+v", cmd)
+	}
+	if cmd, _ := cm.GetCommand("procs"); cmd.Shell != "Get-Process" || !reflect.DeepEqual(cmd.Interpreter, []string{"pwsh", "-Command"}) {
+		t.Errorf("Expected the shared shell command and interpreter, got %+v", cmd)
+	}
+}
+
+// TestLoadRejectsMixedShellCommands tests that a command cannot use both forms
+func TestLoadRejectsMixedShellCommands(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "config.json")
+	writeConfig(t, configFile, `{
+  "interpreter": [],
+  "commands": {
+    "both": {"path": "/bin/ls", "shell": "ls"},
+    "args": {"shell": "ls", "args": ["-l"]},
+    "interp": {"path": "/bin/ls", "interpreter": ["bash", "-c"]}
+  }
+}`)
+	cm, _ := loadConfig(t, configFile)
+	expected := []string{
+		"line 2, column 3: field 'interpreter': interpreter must start with a program",
+		"line 4, column 33: command 'both' field 'shell': set either path or shell, not both",
+		"line 5, column 29: command 'args' field 'args': args cannot be combined with shell; write the arguments into the shell command",
+		"line 6, column 35: command 'interp' field 'interpreter': interpreter requires a shell command",
+	}
+	if got := loadErrors(cm, configFile); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Unexpected errors:\n got: %q\nwant: %q", got, expected)
+	}
+}
@@ -0,0 +1,299 @@
+package control
+
+import (
+	"app-launcher/logger"
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SocketName is the file name of the control socket
+const SocketName = "launcher.sock"
+
+// ioTimeout bounds how long a single request may take to be read or answered
+const ioTimeout = 5 * time.Second
+
+// Supported request actions
+const (
+	ActionRun    = "run"
+	ActionShow   = "show"
+	ActionHide   = "hide"
+	ActionToggle = "toggle"
+	ActionReload = "reload"
+	ActionList   = "list"
+	ActionPs     = "ps"
+	ActionKill   = "kill"
+)
+
+// Request is a control request. Each connection carries exactly one request
+// encoded as a JSON object, for example:
+//
+//	{"action": "run", "command": "vscode", "args": ["~/src/project"]}
+//	{"action": "toggle"}
+//	{"action": "kill", "pid": 4242}
+type Request struct {
+	Action  string   `json:"action"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	PID     int      `json:"pid,omitempty"` // Process to kill, instead of every process of Command
+}
+
+// Response is the answer to a Request
+type Response struct {
+	OK        bool      `json:"ok"`
+	Error     string    `json:"error,omitempty"`
+	Commands  []string  `json:"commands,omitempty"`
+	Processes []Process `json:"processes,omitempty"`
+}
+
+// Process describes a process started by the launcher, as listed by ps and kill
+type Process struct {
+	Name     string    `json:"name"`             // Command name
+	PID      int       `json:"pid"`              // Process ID
+	Args     []string  `json:"args"`             // Arguments it was started with
+	Started  time.Time `json:"started"`          // Start time
+	Running  bool      `json:"running"`          // Whether it is still running
+	Status   string    `json:"status,omitempty"` // How it exited, e.g. "exit status 1"
+	ExitCode int       `json:"exit_code"`        // Exit code once exited, -1 if killed by a signal
+	Duration float64   `json:"duration_seconds"` // Run time so far, or in total once exited
+}
+
+// Handler performs the actions requested over the control socket
+type Handler interface {
+	RunCommand(name string, args []string) error
+	Show()
+	Hide()
+	Toggle()
+	Reload() error
+	List() []string
+	Processes() []Process
+	// Kill terminates the processes of a command, or the process with a PID given in
+	// decimal, and returns them
+	Kill(target string) ([]Process, error)
+}
+
+// Server listens on a Unix domain socket and dispatches requests to a Handler.
+// The socket is only accessible by the current user.
+type Server struct {
+	path     string
+	handler  Handler
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// DefaultSocketPath returns the control socket path: $XDG_RUNTIME_DIR/launcher.sock,
+// or a private per-user directory under the temporary directory if XDG_RUNTIME_DIR is not set
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, SocketName)
+	}
+	if runtime.GOOS == "windows" {
+		// The temporary directory is already per-user on Windows
+		return filepath.Join(os.TempDir(), "launcher", SocketName)
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("launcher-%d", os.Getuid()), SocketName)
+}
+
+// NewServer creates a Server for the socket at path
+func NewServer(path string, handler Handler) (*Server, error) {
+	if path == "" {
+		return nil, fmt.Errorf("socket path cannot be empty")
+	}
+	if handler == nil {
+		return nil, fmt.Errorf("handler cannot be nil")
+	}
+	return &Server{path: path, handler: handler}, nil
+}
+
+// Start creates the socket and begins accepting requests in the background
+func (s *Server) Start() error {
+	if err := prepareSocketDir(filepath.Dir(s.path)); err != nil {
+		logger.Error("Failed to prepare control socket directory: %v", err)
+		return err
+	}
+
+	// A socket file left behind by a crashed instance is removed, but a live one is not
+	if _, err := os.Stat(s.path); err == nil {
+		if conn, err := net.DialTimeout("unix", s.path, time.Second); err == nil {
+			conn.Close()
+			return fmt.Errorf("another launcher is already listening on %s", s.path)
+		}
+		if err := os.Remove(s.path); err != nil {
+			return fmt.Errorf("failed to remove stale control socket: %w", err)
+		}
+	}
+
+	listener, err := net.Listen("unix", s.path)
+	if err != nil {
+		logger.Error("Failed to listen on control socket '%s': %v", s.path, err)
+		return fmt.Errorf("failed to listen on control socket: %w", err)
+	}
+	if err := os.Chmod(s.path, 0600); err != nil {
+		listener.Close()
+		return fmt.Errorf("failed to restrict control socket permissions: %w", err)
+	}
+
+	s.listener = listener
+	s.wg.Add(1)
+	go s.acceptLoop()
+
+	logger.Info("Control socket listening on: %s", s.path)
+	return nil
+}
+
+// Close stops accepting requests and removes the socket
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.wg.Wait()
+	s.listener = nil
+	logger.Info("Control socket closed")
+	return err
+}
+
+// acceptLoop serves connections until the listener is closed
+func (s *Server) acceptLoop() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			logger.Warn("Control socket accept failed: %v", err)
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serve(conn)
+		}()
+	}
+}
+
+// serve handles a single request on conn
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	if err := checkPeer(conn); err != nil {
+		logger.Warn("Rejected control connection: %v", err)
+		writeResponse(conn, Response{Error: err.Error()})
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		writeResponse(conn, Response{Error: fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+
+	logger.Info("Control request: %s %s %v", req.Action, req.Command, req.Args)
+	writeResponse(conn, s.dispatch(req))
+}
+
+// dispatch performs the requested action
+func (s *Server) dispatch(req Request) Response {
+	switch req.Action {
+	case ActionRun:
+		if req.Command == "" {
+			return Response{Error: "run requires a command"}
+		}
+		if err := s.handler.RunCommand(req.Command, req.Args); err != nil {
+			return Response{Error: err.Error()}
+		}
+	case ActionShow:
+		s.handler.Show()
+	case ActionHide:
+		s.handler.Hide()
+	case ActionToggle:
+		s.handler.Toggle()
+	case ActionReload:
+		if err := s.handler.Reload(); err != nil {
+			return Response{Error: err.Error()}
+		}
+	case ActionList:
+		return Response{OK: true, Commands: s.handler.List()}
+	case ActionPs:
+		return Response{OK: true, Processes: s.handler.Processes()}
+	case ActionKill:
+		target := req.Command
+		if req.PID > 0 {
+			target = strconv.Itoa(req.PID)
+		}
+		if target == "" {
+			return Response{Error: "kill requires a command or a pid"}
+		}
+		killed, err := s.handler.Kill(target)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Processes: killed}
+	default:
+		return Response{Error: fmt.Sprintf("unknown action '%s'", req.Action)}
+	}
+	return Response{OK: true}
+}
+
+// writeResponse encodes resp as a single line of JSON
+func writeResponse(conn net.Conn, resp Response) {
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		logger.Warn("Failed to write control response: %v", err)
+	}
+}
+
+// prepareSocketDir creates the socket directory if needed and makes sure
+// that other users cannot access it
+func prepareSocketDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create control socket directory: %w", err)
+	}
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to inspect control socket directory: %w", err)
+	}
+	if info.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("control socket directory '%s' must not be accessible by other users (mode %o)", dir, info.Mode().Perm())
+	}
+	return nil
+}
+
+// Send sends a request to the control socket at path and returns the response.
+// A response with OK set to false is returned as an error.
+func Send(path string, req Request) (Response, error) {
+	conn, err := net.DialTimeout("unix", path, time.Second)
+	if err != nil {
+		return Response{}, fmt.Errorf("launcher is not running (cannot connect to %s): %w", path, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return Response{}, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	var resp Response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return Response{}, fmt.Errorf("failed to read response: %w", err)
+	}
+	if !resp.OK {
+		return resp, errors.New(resp.Error)
+	}
+	return resp, nil
+}
@@ -0,0 +1,259 @@
+package control
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// fakeHandler records the actions it receives
+type fakeHandler struct {
+	mu      sync.Mutex
+	actions []string
+	ran     string
+	args    []string
+}
+
+func (h *fakeHandler) record(action string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.actions = append(h.actions, action)
+}
+
+func (h *fakeHandler) RunCommand(name string, args []string) error {
+	h.record(ActionRun)
+	if name == "missing" {
+		return errors.New("command 'missing' not found")
+	}
+	h.ran = name
+	h.args = args
+	return nil
+}
+
+func (h *fakeHandler) Show()          { h.record(ActionShow) }
+func (h *fakeHandler) Hide()          { h.record(ActionHide) }
+func (h *fakeHandler) Toggle()        { h.record(ActionToggle) }
+func (h *fakeHandler) Reload() error  { h.record(ActionReload); return nil }
+func (h *fakeHandler) List() []string { return []string{"chrome", "code"} }
+
+func (h *fakeHandler) Processes() []Process {
+	return []Process{{Name: "code", PID: 4242, Args: []string{"-n"}, Running: true}}
+}
+
+func (h *fakeHandler) Kill(target string) ([]Process, error) {
+	h.record(ActionKill + " " + target)
+	if target != "code" && target != "4242" {
+		return nil, errors.New("no running process of command '" + target + "'")
+	}
+	return h.Processes(), nil
+}
+
+// startTestServer starts a Server on a socket in a private temporary directory
+func startTestServer(t *testing.T, handler Handler) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix domain socket tests are not run on Windows")
+	}
+
+	dir, err := os.MkdirTemp("", "ctl")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, SocketName)
+
+	server, err := NewServer(path, handler)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return path
+}
+
+// TestServerDispatchesRequests tests that every action reaches the handler
+func TestServerDispatchesRequests(t *testing.T) {
+	handler := &fakeHandler{}
+	path := startTestServer(t, handler)
+
+	for _, action := range []string{ActionShow, ActionHide, ActionToggle, ActionReload} {
+		if _, err := Send(path, Request{Action: action}); err != nil {
+			t.Errorf("Request %s failed: %v", action, err)
+		}
+	}
+
+	if _, err := Send(path, Request{Action: ActionRun, Command: "code", Args: []string{"~/src"}}); err != nil {
+		t.Errorf("Run request failed: %v", err)
+	}
+	if handler.ran != "code" || len(handler.args) != 1 || handler.args[0] != "~/src" {
+		t.Errorf("Expected code to run with [~/src], got %s %v", handler.ran, handler.args)
+	}
+
+	resp, err := Send(path, Request{Action: ActionList})
+	if err != nil {
+		t.Fatalf("List request failed: %v", err)
+	}
+	if len(resp.Commands) != 2 || resp.Commands[0] != "chrome" {
+		t.Errorf("Unexpected list response: %v", resp.Commands)
+	}
+
+	expected := []string{ActionShow, ActionHide, ActionToggle, ActionReload, ActionRun}
+	if len(handler.actions) != len(expected) {
+		t.Fatalf("Expected actions %v, got %v", expected, handler.actions)
+	}
+	for i := range expected {
+		if handler.actions[i] != expected[i] {
+			t.Errorf("Action %d: expected %s, got %s", i, expected[i], handler.actions[i])
+		}
+	}
+}
+
+// TestServerProcessRequests tests listing and killing processes
+func TestServerProcessRequests(t *testing.T) {
+	handler := &fakeHandler{}
+	path := startTestServer(t, handler)
+
+	resp, err := Send(path, Request{Action: ActionPs})
+	if err != nil {
+		t.Fatalf("Ps request failed: %v", err)
+	}
+	if len(resp.Processes) != 1 || resp.Processes[0].PID != 4242 || !resp.Processes[0].Running {
+		t.Errorf("Unexpected ps response: %+v", resp.Processes)
+	}
+
+	if resp, err := Send(path, Request{Action: ActionKill, PID: 4242}); err != nil || len(resp.Processes) != 1 {
+		t.Errorf("Kill by PID failed: %+v, %v", resp, err)
+	}
+	if _, err := Send(path, Request{Action: ActionKill, Command: "code"}); err != nil {
+		t.Errorf("Kill by name failed: %v", err)
+	}
+	if _, err := Send(path, Request{Action: ActionKill, Command: "chrome"}); err == nil || !contains(err.Error(), "no running process") {
+		t.Errorf("Expected the handler's error, got: %v", err)
+	}
+	if _, err := Send(path, Request{Action: ActionKill}); err == nil {
+		t.Error("Expected kill without a target to fail")
+	}
+
+	expected := []string{"kill 4242", "kill code", "kill chrome"}
+	if len(handler.actions) != len(expected) {
+		t.Fatalf("Expected actions %v, got %v", expected, handler.actions)
+	}
+	for i := range expected {
+		if handler.actions[i] != expected[i] {
+			t.Errorf("Action %d: expected %s, got %s", i, expected[i], handler.actions[i])
+		}
+	}
+}
+
+// TestServerReportsErrors tests error responses for bad requests and failed actions
+func TestServerReportsErrors(t *testing.T) {
+	path := startTestServer(t, &fakeHandler{})
+
+	tests := []struct {
+		name string
+		req  Request
+	}{
+		{"unknown action", Request{Action: "explode"}},
+		{"run without command", Request{Action: ActionRun}},
+		{"failing command", Request{Action: ActionRun, Command: "missing"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := Send(path, tt.req)
+			if err == nil {
+				t.Fatal("Expected error response")
+			}
+			if resp.OK || resp.Error == "" {
+				t.Errorf("Expected failed response with message, got %+v", resp)
+			}
+		})
+	}
+
+	// Malformed JSON is answered with an error instead of closing silently
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("not json\n"))
+	buf := make([]byte, 256)
+	n, _ := conn.Read(buf)
+	if !contains(string(buf[:n]), "invalid request") {
+		t.Errorf("Expected invalid request error, got %q", buf[:n])
+	}
+}
+
+// TestSocketPermissions tests that the socket is private to the current user
+func TestSocketPermissions(t *testing.T) {
+	path := startTestServer(t, &fakeHandler{})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat socket: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		t.Errorf("Socket should not be accessible by other users, mode %o", perm)
+	}
+}
+
+// TestStartRefusesSharedDirectory tests that a world-accessible socket directory is rejected
+func TestStartRefusesSharedDirectory(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Directory permissions are not checked on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0777); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+
+	server, _ := NewServer(filepath.Join(dir, SocketName), &fakeHandler{})
+	if err := server.Start(); err == nil {
+		server.Close()
+		t.Error("Expected Start to refuse a directory accessible by other users")
+	}
+}
+
+// TestStartReplacesStaleSocket tests recovery from a socket left behind by a crashed instance
+func TestStartReplacesStaleSocket(t *testing.T) {
+	path := startTestServer(t, &fakeHandler{})
+
+	// A second server must not steal the socket of a live one
+	second, _ := NewServer(path, &fakeHandler{})
+	if err := second.Start(); err == nil {
+		second.Close()
+		t.Fatal("Expected Start to fail while another server is listening")
+	}
+
+	// Simulate a stale socket file
+	stale := filepath.Join(filepath.Dir(path), "stale.sock")
+	listener, err := net.Listen("unix", stale)
+	if err != nil {
+		t.Fatalf("Failed to create socket: %v", err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	third, _ := NewServer(stale, &fakeHandler{})
+	if err := third.Start(); err != nil {
+		t.Fatalf("Expected stale socket to be replaced: %v", err)
+	}
+	third.Close()
+}
+
+// contains reports whether substr is in s
+func contains(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if s[i:i+len(substr)] == substr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package control
+
+import "net"
+
+// checkPeer relies on the permissions of the socket file and its directory
+// on platforms without SO_PEERCRED
+func checkPeer(conn net.Conn) error {
+	return nil
+}
@@ -0,0 +1,215 @@
+// Package desktop reads application entries from freedesktop.org .desktop files.
+//
+// Only the keys the launcher needs are interpreted; see
+// https://specifications.freedesktop.org/desktop-entry-spec/latest/ for the format.
+package desktop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// entryGroup is the group holding the application entry; other groups (actions) are ignored
+const entryGroup = "Desktop Entry"
+
+// Entry is an application described by a .desktop file
+type Entry struct {
+	ID          string   // Desktop file ID, e.g. "org.gnome.Nautilus"
+	File        string   // Path of the .desktop file
+	Type        string   // Entry type; only "Application" entries can be launched
+	Name        string   // Name in the requested locale
+	Comment     string   // Tooltip text in the requested locale
+	Icon        string   // Icon name or path
+	Exec        []string // Program and arguments, with field codes expanded
+	AcceptsArgs bool     // Exec takes files or URLs (%f, %F, %u or %U)
+	Path        string   // Working directory
+	Terminal    bool     // The program must run in a terminal
+	NoDisplay   bool     // The entry should not be shown in menus
+	Hidden      bool     // The entry is deleted and must be treated as absent
+}
+
+// Launchable reports whether the entry is a visible application with a command line
+func (e *Entry) Launchable() bool {
+	return e.Type == "Application" && len(e.Exec) > 0 && !e.NoDisplay && !e.Hidden
+}
+
+// ParseFile reads the .desktop file at path. Localized keys are picked for locale
+// (e.g. "de_DE.UTF-8"); an empty locale uses the untranslated values.
+// The ID is left empty, as it depends on the directory the file was found in.
+func ParseFile(path, locale string) (*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entry, err := parse(f, locale, path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return entry, nil
+}
+
+// Parse reads a .desktop file from r. The %k field code, which refers to the
+// file itself, expands to nothing; use ParseFile to have it filled in.
+func Parse(r io.Reader, locale string) (*Entry, error) {
+	return parse(r, locale, "")
+}
+
+// parse reads a .desktop file that was loaded from file
+func parse(r io.Reader, locale, file string) (*Entry, error) {
+	values, err := readGroup(r, entryGroup)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		File:      file,
+		Type:      values.get("Type"),
+		Name:      values.localized("Name", locale),
+		Comment:   values.localized("Comment", locale),
+		Icon:      values.get("Icon"),
+		Path:      values.get("Path"),
+		Terminal:  values.get("Terminal") == "true",
+		NoDisplay: values.get("NoDisplay") == "true",
+		Hidden:    values.get("Hidden") == "true",
+	}
+
+	if exec := values.get("Exec"); exec != "" {
+		args, err := splitExec(exec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Exec: %w", err)
+		}
+		entry.Exec, entry.AcceptsArgs = expandFieldCodes(args, entry)
+	}
+	return entry, nil
+}
+
+// group holds the raw key/value pairs of one group, with localized keys as "Key[locale]"
+type group map[string]string
+
+// get returns the unescaped value of key
+func (g group) get(key string) string {
+	return unescape(g[key])
+}
+
+// localized returns the value of key for locale, following the matching order of
+// the specification: lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang, key
+func (g group) localized(key, locale string) string {
+	lang, country, modifier := splitLocale(locale)
+	var candidates []string
+	if lang != "" {
+		if country != "" && modifier != "" {
+			candidates = append(candidates, lang+"_"+country+"@"+modifier)
+		}
+		if country != "" {
+			candidates = append(candidates, lang+"_"+country)
+		}
+		if modifier != "" {
+			candidates = append(candidates, lang+"@"+modifier)
+		}
+		candidates = append(candidates, lang)
+	}
+	for _, candidate := range candidates {
+		if value, ok := g[key+"["+candidate+"]"]; ok {
+			return unescape(value)
+		}
+	}
+	return g.get(key)
+}
+
+// splitLocale splits a POSIX locale such as "de_DE.UTF-8@euro" into its parts.
+// The encoding is ignored.
+func splitLocale(locale string) (lang, country, modifier string) {
+	if i := strings.IndexByte(locale, '@'); i >= 0 {
+		locale, modifier = locale[:i], locale[i+1:]
+	}
+	if i := strings.IndexByte(locale, '.'); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexByte(locale, '_'); i >= 0 {
+		locale, country = locale[:i], locale[i+1:]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return "", "", ""
+	}
+	return locale, country, modifier
+}
+
+// readGroup returns the entries of the named group. Blank lines and comments are
+// skipped; the first definition of a key wins.
+func readGroup(r io.Reader, name string) (group, error) {
+	values := make(group)
+	scanner := bufio.NewScanner(r)
+	current := ""
+	found := false
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "["):
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid group header %q", lineNumber, line)
+			}
+			current = line[1 : len(line)-1]
+			found = found || current == name
+			continue
+		}
+
+		if current != name {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key=value, got %q", lineNumber, line)
+		}
+		key = strings.TrimSpace(key)
+		if _, exists := values[key]; !exists {
+			values[key] = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("missing [%s] group", name)
+	}
+	return values, nil
+}
+
+// unescape replaces the escape sequences allowed in string values: \s, \n, \t, \r and \\
+func unescape(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 's':
+			b.WriteByte(' ')
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case '\\':
+			b.WriteByte('\\')
+		default:
+			// Not a string escape; keep it for the Exec quoting rules
+			b.WriteByte('\\')
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,180 @@
+package desktop
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fixtureDirs returns the applications directories under testdata, user directory first
+func fixtureDirs() []string {
+	return []string{
+		filepath.Join("..", "testdata", "desktop", "home", "applications"),
+		filepath.Join("..", "testdata", "desktop", "system", "applications"),
+	}
+}
+
+// TestParseFile tests reading a complete entry from a fixture
+func TestParseFile(t *testing.T) {
+	path := filepath.Join(fixtureDirs()[1], "editor.desktop")
+	entry, err := ParseFile(path, "")
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", path, err)
+	}
+
+	expected := &Entry{
+		File:        path,
+		Type:        "Application",
+		Name:        "Text Editor",
+		Comment:     "Edit text files",
+		Icon:        "accessories-text-editor",
+		Exec:        []string{"/opt/My Editor/editor", "--name", "Text Editor", "--icon", "accessories-text-editor", "--format={json}", "--stamp=%Y"},
+		AcceptsArgs: true,
+		Path:        "/",
+	}
+	if !reflect.DeepEqual(entry, expected) {
+		t.Errorf("Unexpected entry:\n got: %+v\nwant: %+v", entry, expected)
+	}
+	if !entry.Launchable() {
+		t.Error("Expected the entry to be launchable")
+	}
+}
+
+// TestParseLocalizedKeys tests the locale matching order for localized keys
+func TestParseLocalizedKeys(t *testing.T) {
+	path := filepath.Join(fixtureDirs()[1], "editor.desktop")
+	tests := []struct {
+		locale string
+		name   string
+	}{
+		{"", "Text Editor"},
+		{"C", "Text Editor"},
+		{"fr_FR.UTF-8", "Text Editor"},
+		{"de", "Texteditor"},
+		{"de_DE.UTF-8", "Texteditor"},
+		{"de_CH.UTF-8", "Texteditor (Schweiz)"},
+		{"de_CH.UTF-8@euro", "Texteditor (Schweiz)"},
+		{"sr_RS@latin", "Uređivač teksta"},
+	}
+
+	for _, tt := range tests {
+		entry, err := ParseFile(path, tt.locale)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		if entry.Name != tt.name {
+			t.Errorf("Locale %q: expected name %q, got %q", tt.locale, tt.name, entry.Name)
+		}
+	}
+}
+
+// TestParse tests value unescaping and group handling
+func TestParse(t *testing.T) {
+	input := `[Desktop Entry]
+Type=Application
+Name=Tab\tand\sspace
+Exec=/bin/echo "a \"quoted\" \\$HOME" 100%% %k
+Exec=/bin/ignored
+
+[Desktop Action other]
+Exec=/bin/other
+`
+	entry, err := Parse(strings.NewReader(input), "")
+	if err != nil {
+		t.Fatalf("Failed to parse: %v", err)
+	}
+	if entry.Name != "Tab\tand space" {
+		t.Errorf("Expected escapes in Name to be replaced, got %q", entry.Name)
+	}
+	expected := []string{"/bin/echo", `a "quoted" $HOME`, "100%"}
+	if !reflect.DeepEqual(entry.Exec, expected) {
+		t.Errorf("Expected Exec %q, got %q", expected, entry.Exec)
+	}
+	if entry.AcceptsArgs {
+		t.Error("Expected an entry without file or URL codes not to accept arguments")
+	}
+}
+
+// TestParseErrors tests that malformed files are rejected
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"missing group":  "[Other]\nExec=/bin/true\n",
+		"bad header":     "[Desktop Entry\nExec=/bin/true\n",
+		"no separator":   "[Desktop Entry]\nExec\n",
+		"unterminated":   "[Desktop Entry]\nExec=\"/bin/true\n",
+		"invalid escape": "[Desktop Entry]\nExec=\"/bin/\\a\"\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse(strings.NewReader(input), ""); err == nil {
+				t.Errorf("Expected an error for %q", input)
+			}
+		})
+	}
+}
+
+// TestScan tests directory precedence, hidden and invisible entries and file IDs
+func TestScan(t *testing.T) {
+	entries, errs := Scan(append(fixtureDirs(), filepath.Join(t.TempDir(), "missing")), "de_DE.UTF-8")
+
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "broken.desktop") {
+		t.Errorf("Expected only broken.desktop to fail, got: %v", errs)
+	}
+
+	ids := make([]string, 0, len(entries))
+	byID := make(map[string]*Entry)
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+		byID[entry.ID] = entry
+	}
+	expected := []string{"editor", "firefox", "htop", "kde-konsole"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Fatalf("Expected entries %v, got %v", expected, ids)
+	}
+
+	if got := byID["firefox"].Exec[0]; got != "/opt/firefox-nightly/firefox" {
+		t.Errorf("Expected the user entry to shadow the system one, got %s", got)
+	}
+	if got := byID["editor"].Name; got != "Texteditor" {
+		t.Errorf("Expected the localized name, got %s", got)
+	}
+	if !byID["htop"].Terminal {
+		t.Error("Expected htop to require a terminal")
+	}
+}
+
+// TestDirs tests the XDG data directory lookup
+func TestDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/data/home")
+	t.Setenv("XDG_DATA_DIRS", strings.Join([]string{"/data/one", "relative", "/data/two"}, string(filepath.ListSeparator)))
+
+	expected := []string{
+		filepath.Join("/data/home", "applications"),
+		filepath.Join("/data/one", "applications"),
+		filepath.Join("/data/two", "applications"),
+	}
+	if dirs := Dirs(); !reflect.DeepEqual(dirs, expected) {
+		t.Errorf("Expected %v, got %v", expected, dirs)
+	}
+}
+
+// TestLocale tests the precedence of the locale variables
+func TestLocale(t *testing.T) {
+	t.Setenv("LANG", "en_US.UTF-8")
+	t.Setenv("LC_MESSAGES", "")
+	t.Setenv("LC_ALL", "")
+	if locale := Locale(); locale != "en_US.UTF-8" {
+		t.Errorf("Expected LANG to be used, got %s", locale)
+	}
+
+	t.Setenv("LC_MESSAGES", "de_DE.UTF-8")
+	if locale := Locale(); locale != "de_DE.UTF-8" {
+		t.Errorf("Expected LC_MESSAGES to override LANG, got %s", locale)
+	}
+
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	if locale := Locale(); locale != "fr_FR.UTF-8" {
+		t.Errorf("Expected LC_ALL to override everything, got %s", locale)
+	}
+}
@@ -0,0 +1,105 @@
+package desktop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// splitExec splits an unescaped Exec value into arguments.
+//
+// Arguments are separated by spaces. Double quotes group an argument; inside them
+// the characters ", `, $ and \ must be escaped with a backslash. Field codes are
+// left in place for expandFieldCodes.
+func splitExec(exec string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	inQuotes := false
+
+	for i := 0; i < len(exec); i++ {
+		c := exec[i]
+		switch {
+		case inQuotes && c == '\\':
+			if i+1 == len(exec) || !strings.ContainsRune("\"`$\\", rune(exec[i+1])) {
+				return nil, fmt.Errorf("invalid escape at position %d", i)
+			}
+			i++
+			current.WriteByte(exec[i])
+		case inQuotes && c == '"':
+			inQuotes = false
+		case inQuotes:
+			current.WriteByte(c)
+		case c == '"':
+			inQuotes = true
+			inArg = true
+		case c == ' ' || c == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no program")
+	}
+	return args, nil
+}
+
+// expandFieldCodes replaces the field codes in Exec arguments.
+//
+// %f, %F, %u and %U stand for the files or URLs passed to the application; they are
+// removed and reported through acceptsArgs so that typed arguments can be appended
+// instead. %i becomes "--icon <Icon>", %c the name, %k the .desktop file and %% a
+// literal percent sign. Deprecated and unknown codes are removed.
+func expandFieldCodes(args []string, entry *Entry) (expanded []string, acceptsArgs bool) {
+	for _, arg := range args {
+		switch arg {
+		case "%f", "%F", "%u", "%U":
+			acceptsArgs = true
+			continue
+		case "%i":
+			if entry.Icon != "" {
+				expanded = append(expanded, "--icon", entry.Icon)
+			}
+			continue
+		}
+
+		if !strings.Contains(arg, "%") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		var b strings.Builder
+		for i := 0; i < len(arg); i++ {
+			if arg[i] != '%' || i+1 == len(arg) {
+				b.WriteByte(arg[i])
+				continue
+			}
+			i++
+			switch arg[i] {
+			case '%':
+				b.WriteByte('%')
+			case 'c':
+				b.WriteString(entry.Name)
+			case 'k':
+				b.WriteString(entry.File)
+			}
+		}
+		// An argument consisting only of removed codes disappears entirely
+		if b.Len() > 0 {
+			expanded = append(expanded, b.String())
+		}
+	}
+	return expanded, acceptsArgs
+}
@@ -0,0 +1,61 @@
+package desktop
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitExec tests splitting Exec values into arguments
+func TestSplitExec(t *testing.T) {
+	tests := []struct {
+		exec     string
+		expected []string
+	}{
+		{"/usr/bin/app", []string{"/usr/bin/app"}},
+		{"app  --flag   %U", []string{"app", "--flag", "%U"}},
+		{`"/opt/My App/app" --title "Hello World"`, []string{"/opt/My App/app", "--title", "Hello World"}},
+		{`sh -c "echo \"\$HOME\" \` + "`" + `date\` + "`" + ` \\"`, []string{"sh", "-c", "echo \"$HOME\" `date` \\"}},
+		{`app --opt="a b"c`, []string{"app", "--opt=a bc"}},
+		{`app ""`, []string{"app", ""}},
+	}
+	for _, tt := range tests {
+		args, err := splitExec(tt.exec)
+		if err != nil {
+			t.Errorf("splitExec(%q) failed: %v", tt.exec, err)
+			continue
+		}
+		if !reflect.DeepEqual(args, tt.expected) {
+			t.Errorf("splitExec(%q) = %q, want %q", tt.exec, args, tt.expected)
+		}
+	}
+
+	for _, exec := range []string{"", "   ", `"unterminated`, `"bad \n escape"`} {
+		if args, err := splitExec(exec); err == nil {
+			t.Errorf("splitExec(%q) = %q, expected an error", exec, args)
+		}
+	}
+}
+
+// TestExpandFieldCodes tests field code replacement
+func TestExpandFieldCodes(t *testing.T) {
+	entry := &Entry{Name: "Viewer", Icon: "viewer", File: "/apps/viewer.desktop"}
+	tests := []struct {
+		args        []string
+		expected    []string
+		acceptsArgs bool
+	}{
+		{[]string{"viewer"}, []string{"viewer"}, false},
+		{[]string{"viewer", "%f"}, []string{"viewer"}, true},
+		{[]string{"viewer", "--files", "%F"}, []string{"viewer", "--files"}, true},
+		{[]string{"viewer", "%u", "%U"}, []string{"viewer"}, true},
+		{[]string{"viewer", "%i"}, []string{"viewer", "--icon", "viewer"}, false},
+		{[]string{"viewer", "--title=%c", "--desktop=%k"}, []string{"viewer", "--title=Viewer", "--desktop=/apps/viewer.desktop"}, false},
+		{[]string{"viewer", "100%%", "%d", "%D", "%n", "%N", "%v", "%m"}, []string{"viewer", "100%"}, false},
+	}
+	for _, tt := range tests {
+		expanded, acceptsArgs := expandFieldCodes(tt.args, entry)
+		if !reflect.DeepEqual(expanded, tt.expected) || acceptsArgs != tt.acceptsArgs {
+			t.Errorf("expandFieldCodes(%q) = %q, %v; want %q, %v", tt.args, expanded, acceptsArgs, tt.expected, tt.acceptsArgs)
+		}
+	}
+}
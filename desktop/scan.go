@@ -0,0 +1,110 @@
+package desktop
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dirs returns the application directories in order of precedence:
+// $XDG_DATA_HOME/applications (default ~/.local/share/applications), then the
+// applications directory of every entry in $XDG_DATA_DIRS (default /usr/local/share
+// and /usr/share).
+func Dirs() []string {
+	var dirs []string
+	add := func(dir string) {
+		if dir != "" && filepath.IsAbs(dir) {
+			dirs = append(dirs, filepath.Join(dir, "applications"))
+		}
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	add(dataHome)
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share" + string(os.PathListSeparator) + "/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		add(dir)
+	}
+	return dirs
+}
+
+// Locale returns the locale used for messages, from $LC_ALL, $LC_MESSAGES or $LANG
+func Locale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// Scan reads every .desktop file below dirs and returns the launchable entries
+// sorted by ID.
+//
+// An ID found in an earlier directory shadows the same ID in later ones, even if
+// the earlier entry is hidden; that is how users remove system entries. Files that
+// cannot be parsed are skipped and returned as errors. Missing directories are ignored.
+func Scan(dirs []string, locale string) ([]*Entry, []error) {
+	seen := make(map[string]bool)
+	var entries []*Entry
+	var errs []error
+
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path == dir && os.IsNotExist(err) {
+					return fs.SkipDir
+				}
+				errs = append(errs, err)
+				return nil
+			}
+			if d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+
+			id := fileID(dir, path)
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			entry, err := ParseFile(path, locale)
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			entry.ID = id
+			if entry.Launchable() {
+				entries = append(entries, entry)
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return entries, errs
+}
+
+// fileID derives the desktop file ID from the path below an applications directory:
+// subdirectory separators become "-" and the .desktop suffix is dropped
+func fileID(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+	rel = strings.TrimSuffix(filepath.ToSlash(rel), ".desktop")
+	return strings.ReplaceAll(rel, "/", "-")
+}
@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitArgs splits a line typed into the launcher into fields using shell-style quoting.
+//
+// Fields are separated by unquoted whitespace. Single quotes preserve everything
+// literally, double quotes allow \" and \\ escapes, and outside of quotes a backslash
+// escapes whitespace, quotes and backslashes. Any other backslash is kept as is, so
+// Windows paths such as C:\Users\me can be typed without doubling every separator.
+//
+// Examples:
+//
+//	vscode ~/src/project      -> ["vscode", "~/src/project"]
+//	code "my dir"             -> ["code", "my dir"]
+//	open 'it''s'              -> ["open", "its"]
+func SplitArgs(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	inField := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\') {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+
+		case r == '\\' && i+1 < len(runes) && isEscapable(runes[i+1]):
+			i++
+			current.WriteRune(runes[i])
+			inField = true
+
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in input", quote)
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+
+	return fields, nil
+}
+
+// isEscapable reports whether a backslash outside of quotes escapes r
+func isEscapable(r rune) bool {
+	switch r {
+	case ' ', '\t', '\'', '"', '\\':
+		return true
+	}
+	return false
+}
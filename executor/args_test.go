@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestSplitArgs tests shell-style splitting of launcher input
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"empty input", "", nil},
+		{"whitespace only", "   \t ", nil},
+		{"single word", "chrome", []string{"chrome"}},
+		{"command with argument", "vscode ~/src/project", []string{"vscode", "~/src/project"}},
+		{"repeated whitespace", "  code   a\tb  ", []string{"code", "a", "b"}},
+		{"double quoted argument", `code "my dir"`, []string{"code", "my dir"}},
+		{"single quoted argument", `code 'my "dir"'`, []string{"code", `my "dir"`}},
+		{"escaped quote in double quotes", `echo "say \"hi\""`, []string{"echo", `say "hi"`}},
+		{"escaped space", `open my\ file`, []string{"open", "my file"}},
+		{"adjacent quoted parts", `open 'it''s'"!"`, []string{"open", "its!"}},
+		{"empty quoted argument", `run ""`, []string{"run", ""}},
+		{"windows path", `explorer C:\Users\me`, []string{"explorer", `C:\Users\me`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SplitArgs(tt.input)
+			if err != nil {
+				t.Fatalf("SplitArgs(%q) returned error: %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("SplitArgs(%q) = %q, expected %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestSplitArgsUnterminatedQuote tests that an unbalanced quote is reported
+func TestSplitArgsUnterminatedQuote(t *testing.T) {
+	for _, input := range []string{`code "my dir`, `code 'my dir`} {
+		if _, err := SplitArgs(input); err == nil {
+			t.Errorf("SplitArgs(%q) should fail for unterminated quote", input)
+		}
+	}
+}
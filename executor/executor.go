@@ -2,9 +2,14 @@ package executor
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"app-launcher/config"
 	"app-launcher/logger"
@@ -12,52 +17,441 @@ import (
 
 type ConfigProvider interface {
 	GetCommand(name string) (config.Command, bool)
+	CommandNames() []string
 	Load() error
 }
 
+// NameResolver is implemented by configurations that accept other spellings of a
+// command name, such as aliases. CommandName returns the configured name.
+type NameResolver interface {
+	CommandName(name string) (string, bool)
+}
+
+// CommandSource offers commands that are not part of the configuration, such as the
+// executables on $PATH
+type CommandSource interface {
+	GetCommand(name string) (config.Command, bool)
+	CommandNames() []string
+}
+
+// LaunchRecorder records successful launches, e.g. for frecency ranking
+type LaunchRecorder interface {
+	Record(name string) error
+}
+
 // Executor handles command execution and application launching
 type Executor struct {
-	config ConfigProvider
+	config     ConfigProvider
+	fallback   CommandSource
+	clipboard  func() string
+	recorder   LaunchRecorder
+	onError    func(error)
+	onOutput   func(Output)
+	processes  *Registry
+	background sync.WaitGroup // Macros and output captures still running
 }
 
 // NewExecutor creates a new Executor with the specified ConfigManager
 func NewExecutor(cfg ConfigProvider) *Executor {
 	return &Executor{
-		config: cfg,
+		config:    cfg,
+		processes: NewRegistry(),
 	}
 }
 
-// Execute looks up a command by name and launches the corresponding application
-// Returns an error if the command is not found or if the application fails to launch
-func (e *Executor) Execute(commandName string) error {
-	logger.Info("Attempting to execute command: '%s'", commandName)
+// CommandNames returns the names of all commands that can be executed, in sorted order.
+// Names offered by the fallback source are included unless configured commands shadow them.
+func (e *Executor) CommandNames() []string {
+	names := e.config.CommandNames()
+	if e.fallback == nil {
+		return names
+	}
+
+	configured := make(map[string]bool, len(names))
+	for _, name := range names {
+		configured[name] = true
+	}
+	for _, name := range e.fallback.CommandNames() {
+		if !configured[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CommandName returns the name of the command that a typed name refers to: the
+// configured name for an alias or another spelling, or the name itself if it is a
+// command of the fallback source
+func (e *Executor) CommandName(name string) (string, bool) {
+	canonical, _, ok := e.lookup(name)
+	if !ok {
+		return "", false
+	}
+	return canonical, true
+}
+
+// Command returns the command that a typed name refers to, e.g. to show its description
+func (e *Executor) Command(name string) (config.Command, bool) {
+	_, cmd, ok := e.lookup(name)
+	return cmd, ok
+}
+
+// lookup finds the command a typed name refers to in the configuration, then in the
+// fallback source. It returns the configured name along with the command.
+func (e *Executor) lookup(name string) (string, config.Command, bool) {
+	name = strings.TrimSpace(name)
+	if resolver, ok := e.config.(NameResolver); ok {
+		if canonical, ok := resolver.CommandName(name); ok {
+			cmd, exists := e.config.GetCommand(canonical)
+			return canonical, cmd, exists
+		}
+	} else if cmd, ok := e.config.GetCommand(name); ok {
+		return name, cmd, true
+	}
+	if e.fallback != nil {
+		if cmd, ok := e.fallback.GetCommand(name); ok {
+			return name, cmd, true
+		}
+	}
+	return name, config.Command{}, false
+}
+
+// SetFallback sets the source consulted for commands the configuration does not define
+func (e *Executor) SetFallback(source CommandSource) {
+	e.fallback = source
+}
+
+// SetRecorder sets the recorder notified after every successful launch
+func (e *Executor) SetRecorder(recorder LaunchRecorder) {
+	e.recorder = recorder
+}
+
+// SetClipboardReader sets the function used to read the clipboard for the {clipboard} placeholder
+func (e *Executor) SetClipboardReader(read func() string) {
+	e.clipboard = read
+}
+
+// SetErrorHandler sets the function that is told about failures that happen after
+// Execute has returned, such as a failing step of a macro. It may be called from any goroutine.
+func (e *Executor) SetErrorHandler(handle func(error)) {
+	e.onError = handle
+}
+
+// Launch describes a fully resolved process, ready to be started
+type Launch struct {
+	Name    string         // Command name as configured
+	Path    string         // Normalized executable path with placeholders expanded
+	Args    []string       // Final argument list, including user-typed arguments
+	Dir     string         // Working directory (empty for the launcher's own)
+	Env     []string       // Process environment (nil to inherit the launcher's)
+	Command config.Command // The configured command this launch was resolved from
+}
 
-	// Lookup command in configuration
-	cmd, exists := e.config.GetCommand(commandName)
+// Resolve looks up a command by name and resolves it into a Launch without starting anything.
+// Placeholders in the configured path and arguments are expanded (see templateContext).
+// extraArgs fill {query} and positional placeholders; if the command uses neither, they
+// are appended to the configured arguments when the command allows it.
+// Returns an error if the command is not found, a placeholder cannot be expanded,
+// or the command does not accept arguments
+func (e *Executor) Resolve(commandName string, extraArgs ...string) (*Launch, error) {
+	return e.resolve(commandName, extraArgs, 0)
+}
+
+// resolve implements Resolve. pid is the running process an activate command is
+// resolved for, or 0 outside activation (see templateContext).
+func (e *Executor) resolve(commandName string, extraArgs []string, pid int) (*Launch, error) {
+	// Lookup command in configuration, then in the fallback source. Launches are
+	// reported and recorded under the configured name, not an alias.
+	commandName, cmd, exists := e.lookup(commandName)
 	if !exists {
 		err := fmt.Errorf("command '%s' not found", commandName)
 		logger.Error("Command execution failed: %v", err)
-		return err
+		return nil, err
+	}
+	if cmd.Steps != nil {
+		err := fmt.Errorf("command '%s' is a macro and does not run a single process", commandName)
+		logger.Error("Command execution failed: %v", err)
+		return nil, err
+	}
+	return e.prepare(commandName, cmd, extraArgs, pid)
+}
+
+// prepare resolves a command into a Launch under the given name
+func (e *Executor) prepare(commandName string, cmd config.Command, extraArgs []string, pid int) (*Launch, error) {
+	// Expand placeholders before anything is started
+	path, args, err := e.expandCommand(cmd, extraArgs, pid)
+	if err != nil {
+		detailedErr := fmt.Errorf("command '%s': %w", commandName, err)
+		logger.Error("Command execution failed: %v", detailedErr)
+		return nil, detailedErr
+	}
+
+	dir, err := expandString(cmd.Cwd, variableLookup(cmd), cmd.StrictEnv, false)
+	if err != nil {
+		detailedErr := fmt.Errorf("command '%s': cwd: %w", commandName, err)
+		logger.Error("Command execution failed: %v", detailedErr)
+		return nil, detailedErr
 	}
 
 	// Normalize path for Windows (convert forward slashes to backslashes)
-	normalizedPath := normalizePath(cmd.Path)
-	logger.Info("Normalized path for '%s': %s (args: %v)", commandName, normalizedPath, cmd.Args)
+	normalizedPath := normalizePath(path)
+	logger.Info("Normalized path for '%s': %s (args: %v)", commandName, normalizedPath, args)
 
-	// Create the command with arguments
-	execCmd := exec.Command(normalizedPath, cmd.Args...)
+	return &Launch{
+		Name:    commandName,
+		Path:    normalizedPath,
+		Args:    args,
+		Dir:     dir,
+		Env:     buildEnv(cmd),
+		Command: cmd,
+	}, nil
+}
+
+// Execute looks up a command by name and launches the corresponding application.
+// See Resolve for how the command and extraArgs are resolved.
+//
+// A command with a single-instance policy is not launched again while a process
+// started for it by this executor is still running: depending on the policy nothing
+// happens, an *AlreadyRunningError is returned, or its activate command is run
+// instead with the same extraArgs and {pid} set to the newest running process.
+//
+// A macro runs its steps in the background, so Execute returns once it has started;
+// a failing step is reported to the error handler (see SetErrorHandler and Wait).
+// Likewise the output of a command with an output mode reaches the output handler
+// once the process has exited (see SetOutputHandler).
+// Returns an error if the command cannot be resolved or if the application fails to launch
+func (e *Executor) Execute(commandName string, extraArgs ...string) error {
+	logger.Info("Attempting to execute command: '%s' (extra args: %v)", commandName, extraArgs)
+
+	name, _, launched, err := e.execute(commandName, extraArgs, true)
+	if err != nil {
+		return err
+	}
+	if launched {
+		e.record(name)
+	}
+	// Return immediately without waiting for the process to complete
+	return nil
+}
+
+// execute implements Execute without recording the launch. It returns the configured
+// name, a channel that receives the process once it has exited, which is nil if no
+// process was started, and whether the command was launched at all: a running command
+// with the ignore policy is not. With background unset, a macro runs to its end before
+// execute returns.
+func (e *Executor) execute(commandName string, extraArgs []string, background bool) (string, <-chan Process, bool, error) {
+	name, cmd, exists := e.lookup(commandName)
+	if exists && cmd.Steps != nil {
+		err := e.executeMacro(name, cmd, extraArgs, background)
+		return name, nil, err == nil, err
+	}
+	if exists && cmd.SingleInstance != "" {
+		if running, ok := e.processes.newest(name); ok {
+			done, err := e.alreadyRunning(name, cmd, running, extraArgs)
+			return name, done, done != nil, err
+		}
+	}
+
+	launch, err := e.Resolve(commandName, extraArgs...)
+	if err != nil {
+		return name, nil, false, err
+	}
+	done, err := e.start(launch)
+	return launch.Name, done, err == nil, err
+}
+
+// AlreadyRunningError is returned by Execute for a command with the "notify"
+// single-instance policy while a process started for it is still running
+type AlreadyRunningError struct {
+	Name string // Command name as configured
+	PID  int    // The newest running process of the command
+}
+
+func (e *AlreadyRunningError) Error() string {
+	return fmt.Sprintf("command '%s' is already running (PID %d)", e.Name, e.PID)
+}
+
+// alreadyRunning applies the single-instance policy of a command that has a running
+// process. It returns the exit channel of the activate command, if one was started.
+func (e *Executor) alreadyRunning(name string, cmd config.Command, running Process, extraArgs []string) (<-chan Process, error) {
+	switch cmd.SingleInstance {
+	case config.SingleInstanceIgnore:
+		logger.Info("Command '%s' is already running (PID %d), not launching it again", name, running.PID)
+		return nil, nil
+
+	case config.SingleInstanceActivate:
+		logger.Info("Command '%s' is already running (PID %d), running '%s' instead", name, running.PID, cmd.Activate)
+		launch, err := e.resolve(cmd.Activate, extraArgs, running.PID)
+		if err != nil {
+			return nil, fmt.Errorf("command '%s' is already running and could not be activated: %w", name, err)
+		}
+		return e.start(launch)
+	}
+
+	err := &AlreadyRunningError{Name: name, PID: running.PID}
+	logger.Info("Not launching '%s': %v", name, err)
+	return nil, err
+}
+
+// start starts a resolved launch without waiting for it and tracks the process.
+// The returned channel receives the process once it has exited.
+func (e *Executor) start(launch *Launch) (<-chan Process, error) {
+	// Create the command with arguments, working directory and environment
+	execCmd := exec.Command(launch.Path, launch.Args...)
+	execCmd.Dir = launch.Dir
+	execCmd.Env = launch.Env
+	setCommandLine(execCmd, launch)
+
+	// Capture the output of commands that show it. Processes that inherit the pipes
+	// must not keep the capture waiting once the command itself has exited.
+	var stdout, stderr *boundedBuffer
+	if launch.Command.Output != "" {
+		stdout, stderr = &boundedBuffer{limit: maxOutput}, &boundedBuffer{limit: maxOutput}
+		execCmd.Stdout, execCmd.Stderr = stdout, stderr
+		execCmd.WaitDelay = time.Second
+	}
 
 	// Start the process without blocking (don't wait for it to complete)
 	if err := execCmd.Start(); err != nil {
 		// Provide detailed error information
-		detailedErr := fmt.Errorf("failed to launch '%s': %w", commandName, err)
-		logger.Error("Application launch failed for '%s' (path: %s): %v", commandName, normalizedPath, err)
-		return detailedErr
+		detailedErr := fmt.Errorf("failed to launch '%s': %w", launch.Name, err)
+		logger.Error("Application launch failed for '%s' (path: %s): %v", launch.Name, launch.Path, err)
+		return nil, detailedErr
 	}
 
-	logger.Info("Successfully launched application for command '%s' (PID: %d)", commandName, execCmd.Process.Pid)
-	// Return immediately without waiting for the process to complete
-	return nil
+	logger.Info("Successfully launched application for command '%s' (PID: %d)", launch.Name, execCmd.Process.Pid)
+	done := e.processes.track(launch.Name, execCmd)
+	if launch.Command.Output != "" {
+		done = e.capture(launch, execCmd.Process, stdout, stderr, done)
+	}
+	return done, nil
+}
+
+// record notifies the recorder of a launch. A failure to record the launch must not
+// turn a successful launch into an error.
+func (e *Executor) record(name string) {
+	if e.recorder == nil {
+		return
+	}
+	if err := e.recorder.Record(name); err != nil {
+		logger.Warn("Failed to record launch of '%s': %v", name, err)
+	}
+}
+
+// Wait blocks until every macro started by Execute has run its last step and the
+// output of every command with an output mode has been handed over
+func (e *Executor) Wait() {
+	e.background.Wait()
+}
+
+// Processes returns the processes started by Execute that are still running,
+// followed by the ones that exited recently (see Registry)
+func (e *Executor) Processes() []Process {
+	return e.processes.List()
+}
+
+// Kill terminates the running processes started for a command, or the one with the
+// given PID, and returns them. A command may be named by any of its aliases.
+func (e *Executor) Kill(target string) ([]Process, error) {
+	target = strings.TrimSpace(target)
+	if canonical, ok := e.CommandName(target); ok {
+		target = canonical
+	}
+	return e.processes.Kill(target)
+}
+
+// expandCommand expands environment variables, a leading ~ and placeholders in the
+// command's path and arguments and decides what happens to the user-typed arguments.
+// User-typed arguments are never expanded. Shell commands are handled by expandShell.
+func (e *Executor) expandCommand(cmd config.Command, extraArgs []string, pid int) (string, []string, error) {
+	if cmd.Shell != "" {
+		return e.expandShell(cmd, extraArgs, pid)
+	}
+	ctx := &templateContext{
+		args:      extraArgs,
+		pid:       pid,
+		now:       time.Now(),
+		clipboard: e.clipboard,
+	}
+	lookup := variableLookup(cmd)
+	expand := func(s string) (string, error) {
+		s, err := expandString(s, lookup, cmd.StrictEnv, true)
+		if err != nil {
+			return "", err
+		}
+		return ctx.expand(s)
+	}
+
+	path, err := expand(cmd.Path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Build a new slice so the configured arguments are never modified
+	args := make([]string, 0, len(cmd.Args)+len(extraArgs))
+	for _, arg := range cmd.Args {
+		expanded, err := expand(arg)
+		if err != nil {
+			return "", nil, err
+		}
+		args = append(args, expanded)
+	}
+
+	// Arguments consumed by placeholders are not appended again
+	if len(extraArgs) > 0 && !ctx.argsUsed {
+		if !cmd.AllowArgs {
+			return "", nil, fmt.Errorf("does not accept arguments")
+		}
+		args = append(args, extraArgs...)
+	}
+
+	return path, args, nil
+}
+
+// buildEnv returns the environment for a command, or nil to inherit the launcher's
+// environment unchanged. Variables from cmd.Env override inherited ones.
+func buildEnv(cmd config.Command) []string {
+	if len(cmd.Env) == 0 && !cmd.EnvClear {
+		return nil
+	}
+
+	env := []string{}
+	if !cmd.EnvClear {
+		for _, entry := range os.Environ() {
+			key, _, _ := strings.Cut(entry, "=")
+			if _, overridden := lookupEnvKey(cmd.Env, key); !overridden {
+				env = append(env, entry)
+			}
+		}
+	}
+
+	// Sort keys so the resulting environment is deterministic
+	keys := make([]string, 0, len(cmd.Env))
+	for key := range cmd.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+cmd.Env[key])
+	}
+
+	return env
+}
+
+// lookupEnvKey finds key in env, ignoring case on Windows where variable names are case-insensitive
+func lookupEnvKey(env map[string]string, key string) (string, bool) {
+	if value, ok := env[key]; ok {
+		return value, true
+	}
+	if runtime.GOOS == "windows" {
+		for k, value := range env {
+			if strings.EqualFold(k, key) {
+				return value, true
+			}
+		}
+	}
+	return "", false
 }
 
 // normalizePath converts forward slashes to backslashes for Windows compatibility
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"sort"
 	"testing"
 	"time"
 
@@ -26,6 +28,16 @@ func (m *MockConfigManager) GetCommand(name string) (config.Command, bool) {
 	return cmd, exists
 }
 
+// CommandNames はDataのコマンド名をソートして返します
+func (m *MockConfigManager) CommandNames() []string {
+	names := make([]string, 0, len(m.Data.Commands))
+	for name := range m.Data.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Load はMockなので何もしません（インターフェース適合用）
 func (m *MockConfigManager) Load() error {
 	return nil
@@ -695,3 +707,352 @@ func TestExecutePermissionDenied(t *testing.T) {
 		// Don't fail the test as the exact error message may vary by system
 	}
 }
+
+// TestExecuteWithExtraArguments tests that user-typed arguments are only accepted when allowed
+func TestExecuteWithExtraArguments(t *testing.T) {
+	execPath := "/bin/echo"
+	if runtime.GOOS == "windows" {
+		execPath = "C:\\Windows\\System32\\hostname.exe"
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"strict": {Path: execPath, Args: []string{"base"}},
+				"open":   {Path: execPath, Args: []string{"base"}, AllowArgs: true},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	t.Run("rejected when not allowed", func(t *testing.T) {
+		err := executor.Execute("strict", "extra")
+		if err == nil {
+			t.Fatal("Expected error when passing arguments to a command that does not allow them")
+		}
+		if !contains(err.Error(), "does not accept arguments") {
+			t.Errorf("Error should explain that arguments are not accepted: %v", err)
+		}
+	})
+
+	t.Run("appended when allowed", func(t *testing.T) {
+		if err := executor.Execute("open", "my dir"); err != nil {
+			t.Fatalf("Expected command with extra arguments to launch: %v", err)
+		}
+		// The configured arguments must not be modified by appending
+		if args := cm.Data.Commands["open"].Args; len(args) != 1 || args[0] != "base" {
+			t.Errorf("Configured args were modified: %v", args)
+		}
+	})
+}
+
+// TestExecuteExpandsPlaceholders tests placeholder expansion during launch
+func TestExecuteExpandsPlaceholders(t *testing.T) {
+	execPath := "/bin/echo"
+	if runtime.GOOS == "windows" {
+		execPath = "C:\\Windows\\System32\\hostname.exe"
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"gh":      {Path: execPath, Args: []string{"https://github.com/search?q={query}"}},
+				"unknown": {Path: execPath, Args: []string{"{bogus}"}},
+				"fake":    {Path: "/this/path/does/not/exist/{1}", Args: []string{}},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	t.Run("query consumes arguments", func(t *testing.T) {
+		// gh does not set allow_args, but {query} consumes the typed arguments
+		if err := executor.Execute("gh", "fyne", "launcher"); err != nil {
+			t.Fatalf("Expected command with {query} to launch: %v", err)
+		}
+	})
+
+	t.Run("missing query fails before launch", func(t *testing.T) {
+		err := executor.Execute("gh")
+		if err == nil {
+			t.Fatal("Expected error when {query} has no value")
+		}
+		if contains(err.Error(), "failed to launch") {
+			t.Errorf("Error should be reported before launching: %v", err)
+		}
+	})
+
+	t.Run("unknown placeholder fails before launch", func(t *testing.T) {
+		err := executor.Execute("unknown")
+		if err == nil || !contains(err.Error(), "unknown placeholder {bogus}") {
+			t.Errorf("Expected unknown placeholder error, got: %v", err)
+		}
+	})
+
+	t.Run("placeholders in path", func(t *testing.T) {
+		err := executor.Execute("fake", "binary")
+		if err == nil || !contains(err.Error(), "/not/exist/binary") {
+			t.Errorf("Expected launch error mentioning the expanded path, got: %v", err)
+		}
+	})
+}
+
+// mockRecorder は記録された起動を収集します
+type mockRecorder struct {
+	names []string
+}
+
+// Record はコマンド名を記録します
+func (r *mockRecorder) Record(name string) error {
+	r.names = append(r.names, name)
+	return nil
+}
+
+// TestExecuteRecordsSuccessfulLaunches tests that only successful launches are recorded
+func TestExecuteRecordsSuccessfulLaunches(t *testing.T) {
+	execPath := "/bin/echo"
+	if runtime.GOOS == "windows" {
+		execPath = "C:\\Windows\\System32\\hostname.exe"
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"echo": {Path: execPath, Args: []string{}},
+				"fake": {Path: "/this/path/does/not/exist/fake_executable", Args: []string{}},
+			},
+		},
+	}
+	recorder := &mockRecorder{}
+	executor := NewExecutor(cm)
+	executor.SetRecorder(recorder)
+
+	if err := executor.Execute("echo"); err != nil {
+		t.Fatalf("Expected launch to succeed: %v", err)
+	}
+	executor.Execute("fake")
+	executor.Execute("missing")
+
+	if len(recorder.names) != 1 || recorder.names[0] != "echo" {
+		t.Errorf("Expected only 'echo' to be recorded, got %v", recorder.names)
+	}
+}
+
+// TestBuildEnv tests merging and clearing of the process environment
+func TestBuildEnv(t *testing.T) {
+	t.Setenv("LAUNCHER_INHERITED", "inherited")
+	t.Setenv("LAUNCHER_OVERRIDDEN", "old")
+
+	t.Run("no settings inherits environment", func(t *testing.T) {
+		if env := buildEnv(config.Command{}); env != nil {
+			t.Errorf("Expected nil environment to inherit the launcher's, got %d entries", len(env))
+		}
+	})
+
+	t.Run("env merges over inherited variables", func(t *testing.T) {
+		env := buildEnv(config.Command{Env: map[string]string{
+			"LAUNCHER_OVERRIDDEN": "new",
+			"GOFLAGS":             "-mod=vendor",
+		}})
+		assertEnv(t, env, "LAUNCHER_INHERITED", "inherited")
+		assertEnv(t, env, "LAUNCHER_OVERRIDDEN", "new")
+		assertEnv(t, env, "GOFLAGS", "-mod=vendor")
+	})
+
+	t.Run("env_clear starts from an empty environment", func(t *testing.T) {
+		env := buildEnv(config.Command{EnvClear: true, Env: map[string]string{"ONLY": "this"}})
+		if len(env) != 1 || env[0] != "ONLY=this" {
+			t.Errorf("Expected only ONLY=this, got %v", env)
+		}
+	})
+
+	t.Run("env_clear without env gives an empty environment", func(t *testing.T) {
+		env := buildEnv(config.Command{EnvClear: true})
+		if env == nil || len(env) != 0 {
+			t.Errorf("Expected empty non-nil environment, got %v", env)
+		}
+	})
+}
+
+// assertEnv checks that env contains exactly one entry for key with the expected value
+func assertEnv(t *testing.T, env []string, key, expected string) {
+	t.Helper()
+	found := 0
+	for _, entry := range env {
+		if len(entry) > len(key) && entry[:len(key)+1] == key+"=" {
+			found++
+			if entry[len(key)+1:] != expected {
+				t.Errorf("Expected %s=%s, got %s", key, expected, entry)
+			}
+		}
+	}
+	if found != 1 {
+		t.Errorf("Expected exactly one %s entry, found %d", key, found)
+	}
+}
+
+// TestExecuteUsesWorkingDirectoryAndEnvironment tests that cwd and env reach the launched process
+func TestExecuteUsesWorkingDirectoryAndEnvironment(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh to inspect the process environment")
+	}
+
+	workDir := t.TempDir()
+	outFile := filepath.Join(t.TempDir(), "out.txt")
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"inspect": {
+					Path: "/bin/sh",
+					Args: []string{"-c", `echo "$(pwd) $LAUNCHER_VALUE" > "$1"`, "sh", outFile},
+					Cwd:  workDir,
+					Env:  map[string]string{"LAUNCHER_VALUE": "from-config"},
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	if err := executor.Execute("inspect"); err != nil {
+		t.Fatalf("Expected launch to succeed: %v", err)
+	}
+
+	// The process runs in the background, so wait for its output
+	var data []byte
+	for i := 0; i < 50; i++ {
+		data, _ = os.ReadFile(outFile)
+		if len(data) > 0 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	resolvedDir, _ := filepath.EvalSymlinks(workDir)
+	output := string(data)
+	if !contains(output, resolvedDir) && !contains(output, workDir) {
+		t.Errorf("Expected process to run in %s, got output %q", workDir, output)
+	}
+	if !contains(output, "from-config") {
+		t.Errorf("Expected LAUNCHER_VALUE from config, got output %q", output)
+	}
+}
+
+// TestResolveDoesNotLaunch tests resolving a command into its final path and arguments
+func TestResolveDoesNotLaunch(t *testing.T) {
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"search": {
+					Path: "/this/path/does/not/exist/browser",
+					Args: []string{"--new-tab", "https://example.com/?q={query}"},
+					Cwd:  "/tmp",
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	launch, err := executor.Resolve("search", "go", "fyne")
+	if err != nil {
+		t.Fatalf("Resolve should not try to start the executable: %v", err)
+	}
+	if launch.Name != "search" || launch.Dir != "/tmp" {
+		t.Errorf("Unexpected launch: %+v", launch)
+	}
+	if len(launch.Args) != 2 || launch.Args[1] != "https://example.com/?q=go fyne" {
+		t.Errorf("Unexpected resolved args: %q", launch.Args)
+	}
+
+	if _, err := executor.Resolve("missing"); err == nil {
+		t.Error("Expected error resolving unknown command")
+	}
+}
+
+// TestResolveUsesFallback tests that the fallback source is consulted only for
+// commands missing from the configuration
+func TestResolveUsesFallback(t *testing.T) {
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"editor": {Path: "/configured/editor", Args: []string{}},
+			},
+		},
+	}
+	fallback := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"editor": {Path: "/usr/bin/editor", Args: []string{}, AllowArgs: true},
+				"grep":   {Path: "/usr/bin/grep", Args: []string{}, AllowArgs: true},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	if _, err := executor.Resolve("grep"); err == nil {
+		t.Fatal("Expected unknown command without a fallback")
+	}
+
+	executor.SetFallback(fallback)
+	launch, err := executor.Resolve("grep", "-r", "TODO")
+	if err != nil {
+		t.Fatalf("Expected the fallback command to resolve: %v", err)
+	}
+	if launch.Path != normalizePath("/usr/bin/grep") || len(launch.Args) != 2 {
+		t.Errorf("Unexpected launch: %+v", launch)
+	}
+
+	if launch, _ := executor.Resolve("editor"); launch.Path != normalizePath("/configured/editor") {
+		t.Errorf("Expected the configured command to win, got %s", launch.Path)
+	}
+
+	expected := []string{"editor", "grep"}
+	if names := executor.CommandNames(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Expected names %v, got %v", expected, names)
+	}
+}
+
+// aliasConfig is a MockConfigManager that also resolves aliases
+type aliasConfig struct {
+	MockConfigManager
+	aliases map[string]string
+}
+
+// CommandName resolves an alias or a command name
+func (a *aliasConfig) CommandName(name string) (string, bool) {
+	if canonical, ok := a.aliases[name]; ok {
+		return canonical, true
+	}
+	_, ok := a.Data.Commands[name]
+	return name, ok
+}
+
+// TestResolveUsesCanonicalName tests that aliases are resolved to the configured name
+func TestResolveUsesCanonicalName(t *testing.T) {
+	cm := &aliasConfig{
+		MockConfigManager: MockConfigManager{
+			Data: config.Config{
+				Commands: map[string]config.Command{
+					"chrome": {Path: "/bin/chrome", Args: []string{}},
+				},
+			},
+		},
+		aliases: map[string]string{"ch": "chrome"},
+	}
+	executor := NewExecutor(cm)
+
+	for _, name := range []string{"ch", " ch ", "chrome"} {
+		launch, err := executor.Resolve(name)
+		if err != nil {
+			t.Fatalf("Failed to resolve %q: %v", name, err)
+		}
+		if launch.Name != "chrome" {
+			t.Errorf("Expected %q to resolve to chrome, got %s", name, launch.Name)
+		}
+	}
+	if cmd, ok := executor.Command("ch"); !ok || cmd.Path != "/bin/chrome" {
+		t.Errorf("Expected the command behind the alias, got %+v", cmd)
+	}
+	if _, ok := executor.CommandName("firefox"); ok {
+		t.Error("Expected unknown names not to resolve")
+	}
+}
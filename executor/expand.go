@@ -0,0 +1,163 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"app-launcher/config"
+)
+
+// variableLookup returns the value of an environment variable as the launched process
+// will see it: the command's own env first, then the launcher's environment unless the
+// command clears it.
+func variableLookup(cmd config.Command) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		if value, ok := lookupEnvKey(cmd.Env, name); ok {
+			return value, true
+		}
+		if cmd.EnvClear {
+			return "", false
+		}
+		return os.LookupEnv(name)
+	}
+}
+
+// expandVariables replaces environment variable references in s.
+//
+// Supported forms are $NAME, ${NAME} and %NAME%, where NAME starts with a letter or
+// underscore. $$ and %% stand for literal $ and % signs. An unset variable is an error
+// in strict mode; otherwise $NAME and ${NAME} become empty, as in a shell, and %NAME%
+// is kept as written, as in cmd.exe, so that text like "%Y%m%d" survives.
+// With template set, substituted values have their braces doubled so that they are not
+// taken for placeholders.
+func expandVariables(s string, lookup func(string) (string, bool), strict, template bool) (string, error) {
+	if !strings.ContainsAny(s, "$%") {
+		return s, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '$' && c != '%' {
+			out.WriteByte(c)
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == c {
+			out.WriteByte(c)
+			i++
+			continue
+		}
+
+		name, length := variableReference(s[i:])
+		if length == 0 {
+			out.WriteByte(c)
+			continue
+		}
+
+		value, ok := lookup(name)
+		switch {
+		case !ok && strict:
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		case !ok && c == '%':
+			out.WriteString(s[i : i+length])
+		case template:
+			out.WriteString(escapeBraces(value))
+		default:
+			out.WriteString(value)
+		}
+		i += length - 1
+	}
+	return out.String(), nil
+}
+
+// variableReference parses the variable reference at the start of s and returns the
+// variable name and the length of the reference, or a length of 0 if s does not start
+// with a complete reference
+func variableReference(s string) (string, int) {
+	switch {
+	case strings.HasPrefix(s, "${"):
+		end := strings.IndexByte(s, '}')
+		if end < 0 || !isVariableName(s[2:end], false) {
+			return "", 0
+		}
+		return s[2:end], end + 1
+
+	case s[0] == '%':
+		end := strings.IndexByte(s[1:], '%')
+		if end < 0 || !isVariableName(s[1:end+1], true) {
+			return "", 0
+		}
+		return s[1 : end+1], end + 2
+
+	default:
+		end := 1
+		for end < len(s) && isVariableChar(s[end], end == 1, false) {
+			end++
+		}
+		if end == 1 {
+			return "", 0
+		}
+		return s[1:end], end
+	}
+}
+
+// isVariableName reports whether name is a valid variable name. Windows names may also
+// contain parentheses, as in ProgramFiles(x86).
+func isVariableName(name string, windows bool) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isVariableChar(name[i], i == 0, windows) {
+			return false
+		}
+	}
+	return true
+}
+
+// isVariableChar reports whether c may appear in a variable name at the given position
+func isVariableChar(c byte, first, windows bool) bool {
+	switch {
+	case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+		return true
+	case c >= '0' && c <= '9':
+		return !first
+	case c == '(' || c == ')':
+		return windows && !first
+	}
+	return false
+}
+
+// expandHome replaces a leading ~ with the current user's home directory. With template
+// set, braces in the home directory are doubled as in expandVariables.
+func expandHome(s string, template bool) (string, error) {
+	if s != "~" && !strings.HasPrefix(s, "~/") && !strings.HasPrefix(s, `~\`) {
+		return s, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot expand '~': %w", err)
+	}
+	if template {
+		home = escapeBraces(home)
+	}
+	return home + s[1:], nil
+}
+
+// expandString expands the environment variables and then a leading ~ in s
+func expandString(s string, lookup func(string) (string, bool), strict, template bool) (string, error) {
+	s, err := expandVariables(s, lookup, strict, template)
+	if err != nil {
+		return "", err
+	}
+	return expandHome(s, template)
+}
+
+// escapeBraces doubles braces so that the template expands them to literal braces
+func escapeBraces(s string) string {
+	if !strings.ContainsAny(s, "{}") {
+		return s
+	}
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(s)
+}
@@ -0,0 +1,143 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"app-launcher/config"
+)
+
+// TestExpandVariables tests the supported variable reference forms
+func TestExpandVariables(t *testing.T) {
+	vars := map[string]string{
+		"HOME":              "/home/me",
+		"APP_DIR":           "/opt/app",
+		"LOCALAPPDATA":      `C:\Users\me\AppData\Local`,
+		"ProgramFiles(x86)": `C:\Program Files (x86)`,
+		"BRACES":            "{x}",
+		"EMPTY":             "",
+	}
+	lookup := func(name string) (string, bool) {
+		value, ok := vars[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"$HOME/bin/tool", "/home/me/bin/tool"},
+		{"${APP_DIR}bin", "/opt/appbin"},
+		{`%LOCALAPPDATA%\Programs\tool.exe`, `C:\Users\me\AppData\Local\Programs\tool.exe`},
+		{`%ProgramFiles(x86)%\Tool`, `C:\Program Files (x86)\Tool`},
+		{"$HOME-$APP_DIR", "/home/me-/opt/app"},
+		{"$EMPTY|${EMPTY}|%EMPTY%", "||"},
+		{"$UNSET|${UNSET}|x", "||x"},
+		{"%UNSET%", "%UNSET%"},
+		{"+%Y%m%d", "+%Y%m%d"},
+		{"100%", "100%"},
+		{"https://example.com/?q=a%20b", "https://example.com/?q=a%20b"},
+		{"$$HOME and 100%%", "$HOME and 100%"},
+		{"$ $1 ${ ${1x} $", "$ $1 ${ ${1x} $"},
+		{"$BRACES", "{{x}}"},
+	}
+	for _, tt := range tests {
+		result, err := expandVariables(tt.input, lookup, false, true)
+		if err != nil {
+			t.Errorf("expandVariables(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("expandVariables(%q) = %q, want %q", tt.input, result, tt.expected)
+		}
+	}
+
+	if result, _ := expandVariables("$BRACES", lookup, false, false); result != "{x}" {
+		t.Errorf("Expected braces to be kept outside templates, got %q", result)
+	}
+
+	for _, input := range []string{"$UNSET", "${UNSET}", "%UNSET%"} {
+		_, err := expandVariables(input, lookup, true, true)
+		if err == nil || !strings.Contains(err.Error(), "UNSET is not set") {
+			t.Errorf("Expected strict mode to reject %q, got: %v", input, err)
+		}
+	}
+	if result, err := expandVariables("$EMPTY", lookup, true, true); err != nil || result != "" {
+		t.Errorf("Expected a set but empty variable to pass strict mode, got %q, %v", result, err)
+	}
+}
+
+// TestExpandHome tests expansion of a leading ~
+func TestExpandHome(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+
+	tests := map[string]string{
+		"~":         home,
+		"~/notes":   home + "/notes",
+		`~\notes`:   home + `\notes`,
+		"~user/x":   "~user/x",
+		"a/~/b":     "a/~/b",
+		"--dir=~/x": "--dir=~/x",
+	}
+	for input, expected := range tests {
+		result, err := expandHome(input, false)
+		if err != nil || result != expected {
+			t.Errorf("expandHome(%q) = %q, %v; want %q", input, result, err, expected)
+		}
+	}
+}
+
+// TestResolveExpandsEnvironment tests expansion in path, args and cwd during Resolve
+func TestResolveExpandsEnvironment(t *testing.T) {
+	tools := t.TempDir()
+	t.Setenv("LAUNCHER_TOOLS", tools)
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory: %v", err)
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"tool": {
+					Path:      "$LAUNCHER_TOOLS/bin/tool",
+					Args:      []string{"--config=${LAUNCHER_TOOLS}/tool.cfg", "%PROJECT%", "~/notes"},
+					Cwd:       "$LAUNCHER_TOOLS",
+					Env:       map[string]string{"PROJECT": "demo"},
+					AllowArgs: true,
+				},
+				"strict": {
+					Path:      "$LAUNCHER_UNSET_VARIABLE/tool",
+					Args:      []string{},
+					StrictEnv: true,
+				},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	launch, err := executor.Resolve("tool", "$HOME")
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if launch.Path != normalizePath(filepath.Join(tools, "bin", "tool")) {
+		t.Errorf("Unexpected path: %s", launch.Path)
+	}
+	expected := []string{"--config=" + tools + "/tool.cfg", "demo", filepath.Join(home, "notes"), "$HOME"}
+	if strings.Join(launch.Args, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected args %q, got %q", expected, launch.Args)
+	}
+	if launch.Dir != tools {
+		t.Errorf("Expected cwd %s, got %s", tools, launch.Dir)
+	}
+
+	if _, err := executor.Resolve("strict"); err == nil || !strings.Contains(err.Error(), "LAUNCHER_UNSET_VARIABLE is not set") {
+		t.Errorf("Expected strict mode to fail, got: %v", err)
+	}
+}
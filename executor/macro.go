@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"fmt"
+	"time"
+
+	"app-launcher/config"
+	"app-launcher/logger"
+)
+
+// executeMacro runs the steps of a macro, in the background or before it returns.
+// Macros do not accept typed arguments.
+func (e *Executor) executeMacro(name string, cmd config.Command, extraArgs []string, background bool) error {
+	if len(extraArgs) > 0 {
+		err := fmt.Errorf("command '%s': does not accept arguments", name)
+		logger.Error("Command execution failed: %v", err)
+		return err
+	}
+	if !background {
+		return e.runMacro(name, cmd)
+	}
+
+	logger.Info("Starting macro '%s' with %d steps", name, len(cmd.Steps))
+	e.background.Add(1)
+	go func() {
+		defer e.background.Done()
+		if err := e.runMacro(name, cmd); err != nil && e.onError != nil {
+			e.onError(err)
+		}
+	}()
+	return nil
+}
+
+// runMacro runs the steps of a macro in order and returns the error of the step that
+// aborted it, if any
+func (e *Executor) runMacro(name string, cmd config.Command) error {
+	for i, step := range cmd.Steps {
+		if step.Delay != "" {
+			delay, err := time.ParseDuration(step.Delay)
+			if err != nil {
+				return fmt.Errorf("macro '%s' step %d: invalid delay: %w", name, i+1, err)
+			}
+			time.Sleep(delay)
+		}
+
+		err := e.runStep(name, cmd, step)
+		if err == nil {
+			continue
+		}
+		err = fmt.Errorf("macro '%s' step %d: %w", name, i+1, err)
+		if step.OnError == config.OnErrorContinue {
+			logger.Warn("%v; continuing with the next step", err)
+			continue
+		}
+		logger.Error("%v; skipping the remaining steps", err)
+		return err
+	}
+	logger.Info("Macro '%s' finished", name)
+	return nil
+}
+
+// runStep runs a single step and waits for it as the step asks. Processes started
+// from a path or shell step are tracked under the name of the macro.
+func (e *Executor) runStep(macro string, cmd config.Command, step config.Step) error {
+	var done <-chan Process
+	if step.Command != "" {
+		var err error
+		if _, done, _, err = e.execute(step.Command, step.Args, false); err != nil {
+			return err
+		}
+	} else {
+		launch, err := e.prepare(macro, config.Command{
+			Path:        step.Path,
+			Args:        step.Args,
+			Shell:       step.Shell,
+			Interpreter: cmd.Interpreter,
+			Cwd:         cmd.Cwd,
+			Env:         cmd.Env,
+			EnvClear:    cmd.EnvClear,
+			StrictEnv:   cmd.StrictEnv,
+		}, nil, 0)
+		if err != nil {
+			return err
+		}
+		if done, err = e.start(launch); err != nil {
+			return err
+		}
+	}
+
+	if step.Wait != config.WaitExit || done == nil {
+		return nil
+	}
+	if p := <-done; p.Code != 0 {
+		return fmt.Errorf("process %d of '%s' failed: %s", p.PID, p.Name, p.Status)
+	}
+	return nil
+}
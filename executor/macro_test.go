@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"app-launcher/config"
+)
+
+// TestMacroRunsSteps tests that steps run in order, that waiting for exit and
+// delays hold back the next step, and that nested macros run to their end
+func TestMacroRunsSteps(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	appendLine := func(text string) string { return "echo " + text + " >> " + log }
+	cm := &aliasConfig{
+		MockConfigManager: MockConfigManager{
+			Data: config.Config{
+				Commands: map[string]config.Command{
+					"note": {Shell: appendLine("{1}"), AllowArgs: true},
+					"inner": {Steps: []config.Step{
+						{Shell: "sleep 0.2; " + appendLine("inner"), Wait: config.WaitExit},
+					}},
+					"standup": {Cwd: dir, Env: map[string]string{"WHO": "team"}, Steps: []config.Step{
+						{Command: "n", Args: []string{"first"}, Wait: config.WaitExit},
+						{Shell: "sleep 0.2; " + appendLine("$WHO"), Wait: config.WaitExit},
+						{Command: "inner"},
+						{Path: "/bin/sh", Args: []string{"-c", appendLine("last")}, Delay: "100ms", Wait: config.WaitExit},
+					}},
+				},
+			},
+		},
+		aliases: map[string]string{"n": "note"},
+	}
+	recorder := &mockRecorder{}
+	executor := NewExecutor(cm)
+	executor.SetRecorder(recorder)
+	var errs []error
+	executor.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	started := time.Now()
+	if err := executor.Execute("standup"); err != nil {
+		t.Fatalf("Failed to start macro: %v", err)
+	}
+	if time.Since(started) > 150*time.Millisecond {
+		t.Error("Expected Execute to return while the macro runs")
+	}
+	executor.Wait()
+
+	data, err := os.ReadFile(log)
+	if err != nil || string(data) != "first\nteam\ninner\nlast\n" {
+		t.Errorf("Unexpected step order %q, %v", data, err)
+	}
+	if len(errs) > 0 {
+		t.Errorf("Unexpected errors: %v", errs)
+	}
+	if strings.Join(recorder.names, " ") != "standup" {
+		t.Errorf("Expected only the macro to be recorded, got %v", recorder.names)
+	}
+	for _, p := range executor.Processes() {
+		if p.Name != "note" && p.Name != "inner" && p.Name != "standup" {
+			t.Errorf("Unexpected process name %q", p.Name)
+		}
+	}
+
+	if err := executor.Execute("standup", "x"); err == nil || !strings.Contains(err.Error(), "does not accept arguments") {
+		t.Errorf("Expected a macro to refuse arguments, got: %v", err)
+	}
+	if _, err := executor.Resolve("standup"); err == nil || !strings.Contains(err.Error(), "is a macro") {
+		t.Errorf("Expected a macro not to resolve to a process, got: %v", err)
+	}
+}
+
+// TestMacroStepFailures tests that a failing step aborts the macro unless the step
+// continues on error, and that the failure reaches the error handler
+func TestMacroStepFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	log := filepath.Join(t.TempDir(), "log")
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"tolerant": {Steps: []config.Step{
+					{Shell: "exit 2", Wait: config.WaitExit, OnError: config.OnErrorContinue},
+					{Path: "/does/not/exist", OnError: config.OnErrorContinue},
+					{Shell: "echo done >> " + log, Wait: config.WaitExit},
+				}},
+				"strict": {Steps: []config.Step{
+					{Shell: "exit 3", Wait: config.WaitExit},
+					{Shell: "echo unreachable >> " + log},
+				}},
+				"unwaited": {Steps: []config.Step{
+					{Shell: "exit 4"},
+				}},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+	var mu sync.Mutex
+	var errs []string
+	executor.SetErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err.Error())
+	})
+
+	for _, name := range []string{"tolerant", "strict", "unwaited"} {
+		if err := executor.Execute(name); err != nil {
+			t.Fatalf("Failed to start '%s': %v", name, err)
+		}
+		executor.Wait()
+	}
+
+	data, _ := os.ReadFile(log)
+	if string(data) != "done\n" {
+		t.Errorf("Expected only the tolerant macro to reach its last step, got %q", data)
+	}
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], "macro 'strict' step 1: process ") || !strings.HasSuffix(errs[0], "failed: exit status 3") {
+		t.Errorf("Expected one error for the strict macro, got %q", errs)
+	}
+}
@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"app-launcher/logger"
+)
+
+// maxOutput is how many bytes of stdout and of stderr are kept per process; the rest is dropped
+const maxOutput = 64 << 10
+
+// defaultOutputTimeout is how long a command with an output mode may run by default
+const defaultOutputTimeout = 10 * time.Second
+
+// Output is what a command with an output mode wrote, captured once it has exited
+type Output struct {
+	Name      string        // Command name as configured
+	Mode      string        // config.OutputShow, OutputClipboard or OutputNotify
+	Stdout    string        // Captured standard output
+	Stderr    string        // Captured standard error
+	Truncated bool          // Output beyond maxOutput was dropped
+	TimedOut  bool          // The process was killed because it ran too long
+	Timeout   time.Duration // How long the process was allowed to run
+	Process   Process       // How the process exited
+}
+
+// Err returns why the command failed, or nil if it exited successfully
+func (o Output) Err() error {
+	switch {
+	case o.TimedOut:
+		return fmt.Errorf("command '%s' did not finish within %s and was killed", o.Name, o.Timeout)
+	case o.Process.Code != 0:
+		return fmt.Errorf("command '%s' failed: %s", o.Name, o.Process.Status)
+	}
+	return nil
+}
+
+// Text returns the output to present: stdout followed by stderr, without the
+// trailing newline and with a note if output was dropped
+func (o Output) Text() string {
+	text := strings.TrimRight(o.Stdout, "\r\n")
+	if stderr := strings.TrimRight(o.Stderr, "\r\n"); stderr != "" {
+		if text != "" {
+			text += "\n"
+		}
+		text += stderr
+	}
+	if o.Truncated {
+		text += "\n[output truncated]"
+	}
+	return text
+}
+
+// SetOutputHandler sets the function that receives the output of commands with an
+// output mode. It is called from a background goroutine once the process has exited.
+func (e *Executor) SetOutputHandler(handle func(Output)) {
+	e.onOutput = handle
+}
+
+// boundedBuffer keeps the first limit bytes written to it and counts the rest, so
+// that a chatty process never blocks on a full pipe
+type boundedBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	limit   int
+	dropped int
+}
+
+// Write stores what fits and drops the rest. It never fails.
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	keep := min(len(p), b.limit-len(b.data))
+	b.data = append(b.data, p[:keep]...)
+	b.dropped += len(p) - keep
+	return len(p), nil
+}
+
+// String returns the kept bytes
+func (b *boundedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.data)
+}
+
+// capture waits in the background for a process whose output is collected in stdout
+// and stderr, kills it once the command's timeout has passed and hands the result to
+// the output handler. The returned channel receives the process once it has exited.
+func (e *Executor) capture(launch *Launch, process *os.Process, stdout, stderr *boundedBuffer, done <-chan Process) <-chan Process {
+	out := Output{Name: launch.Name, Mode: launch.Command.Output, Timeout: defaultOutputTimeout}
+	if launch.Command.Timeout != "" {
+		if timeout, err := time.ParseDuration(launch.Command.Timeout); err == nil && timeout > 0 {
+			out.Timeout = timeout
+		}
+	}
+
+	exited := make(chan Process, 1)
+	e.background.Add(1)
+	go func() {
+		defer e.background.Done()
+		defer close(exited)
+
+		timer := time.NewTimer(out.Timeout)
+		defer timer.Stop()
+		select {
+		case out.Process = <-done:
+		case <-timer.C:
+			logger.Warn("Command '%s' did not finish within %s, killing it", out.Name, out.Timeout)
+			out.TimedOut = true
+			if err := process.Kill(); err != nil {
+				logger.Warn("Failed to kill process %d of command '%s': %v", process.Pid, out.Name, err)
+			}
+			out.Process = <-done
+		}
+
+		out.Stdout, out.Stderr = stdout.String(), stderr.String()
+		out.Truncated = stdout.dropped > 0 || stderr.dropped > 0
+		logger.Info("Captured %d bytes of output from command '%s' for %s", len(out.Stdout)+len(out.Stderr), out.Name, out.Mode)
+		if e.onOutput != nil {
+			e.onOutput(out)
+		} else if err := out.Err(); err != nil {
+			logger.Warn("Output of command '%s' is not shown: %v", out.Name, err)
+		}
+		exited <- out.Process
+	}()
+	return exited
+}
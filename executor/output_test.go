@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"app-launcher/config"
+)
+
+// TestBoundedBuffer tests that only the first bytes are kept and writes never fail
+func TestBoundedBuffer(t *testing.T) {
+	b := &boundedBuffer{limit: 5}
+	for _, chunk := range []string{"abc", "defg", "hij"} {
+		if n, err := b.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Errorf("Write(%q) = %d, %v", chunk, n, err)
+		}
+	}
+	if b.String() != "abcde" || b.dropped != 5 {
+		t.Errorf("Expected \"abcde\" with 5 bytes dropped, got %q and %d", b.String(), b.dropped)
+	}
+}
+
+// TestOutputText tests how captured output is presented
+func TestOutputText(t *testing.T) {
+	tests := []struct {
+		output   Output
+		expected string
+	}{
+		{Output{Stdout: "3f2a\n"}, "3f2a"},
+		{Output{Stdout: "M go.mod\n", Stderr: "warning\n"}, "M go.mod\nwarning"},
+		{Output{Stderr: "fatal: not a git repository\n"}, "fatal: not a git repository"},
+		{Output{Stdout: "aaaa", Truncated: true}, "aaaa\n[output truncated]"},
+	}
+	for _, tt := range tests {
+		if text := tt.output.Text(); text != tt.expected {
+			t.Errorf("Text() = %q, expected %q", text, tt.expected)
+		}
+	}
+}
+
+// TestExecuteCapturesOutput tests that the output of a command with an output mode
+// reaches the handler, with failures and timeouts reported
+func TestExecuteCapturesOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"date":  {Shell: "echo today; echo note >&2", Output: config.OutputShow},
+				"fail":  {Shell: "echo broken >&2; exit 2", Output: config.OutputNotify},
+				"slow":  {Shell: "echo started; exec sleep 5", Output: config.OutputClipboard, Timeout: "200ms"},
+				"child": {Shell: "echo parent; sleep 3 &", Output: config.OutputShow},
+				"flood": {Path: "/bin/sh", Args: []string{"-c", "head -c 200000 /dev/zero"}, Output: config.OutputShow},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+	var mu sync.Mutex
+	outputs := make(map[string]Output)
+	executor.SetOutputHandler(func(out Output) {
+		mu.Lock()
+		defer mu.Unlock()
+		outputs[out.Name] = out
+	})
+
+	started := time.Now()
+	for _, name := range []string{"date", "fail", "slow", "child", "flood"} {
+		if err := executor.Execute(name); err != nil {
+			t.Fatalf("Failed to launch '%s': %v", name, err)
+		}
+	}
+	executor.Wait()
+	if elapsed := time.Since(started); elapsed > 3*time.Second {
+		t.Errorf("Expected the capture to end soon after the commands, took %s", elapsed)
+	}
+
+	if out := outputs["date"]; out.Stdout != "today\n" || out.Stderr != "note\n" || out.Mode != config.OutputShow || out.Err() != nil {
+		t.Errorf("Unexpected output: %+v", out)
+	}
+	if out := outputs["fail"]; out.Err() == nil || !strings.Contains(out.Err().Error(), "exit status 2") || out.Text() != "broken" {
+		t.Errorf("Expected the failure to be reported, got %+v", out)
+	}
+	if out := outputs["slow"]; !out.TimedOut || out.Stdout != "started\n" || !strings.Contains(out.Err().Error(), "did not finish within 200ms") {
+		t.Errorf("Expected the slow command to time out, got %+v", out)
+	}
+	if out := outputs["child"]; out.Stdout != "parent\n" || out.TimedOut {
+		t.Errorf("Expected the output of the parent, got %+v", out)
+	}
+	if out := outputs["flood"]; len(out.Stdout) != maxOutput || !out.Truncated || out.Err() != nil {
+		t.Errorf("Expected %d bytes of truncated output, got %d (truncated %v, %v)", maxOutput, len(out.Stdout), out.Truncated, out.Err())
+	}
+}
@@ -0,0 +1,199 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"app-launcher/logger"
+)
+
+// maxFinished is how many exited processes the registry remembers for listing
+const maxFinished = 20
+
+// Process describes a process started by the executor
+type Process struct {
+	Name    string    // Command name as configured
+	PID     int       // Operating system process ID
+	Path    string    // Executable that was started
+	Args    []string  // Arguments it was started with
+	Started time.Time // When it was started
+	Ended   time.Time // When it exited; zero while it is running
+	Status  string    // How it exited, e.g. "exit status 1" or "signal: terminated"; empty while running
+	Code    int       // Exit code, or -1 if it was killed by a signal
+}
+
+// Running reports whether the process has not exited yet
+func (p Process) Running() bool {
+	return p.Ended.IsZero()
+}
+
+// Duration returns how long the process ran, or has been running until now
+func (p Process) Duration(now time.Time) time.Duration {
+	if p.Running() {
+		return now.Sub(p.Started)
+	}
+	return p.Ended.Sub(p.Started)
+}
+
+// Registry tracks the processes started by the executor.
+//
+// Every process is waited for in the background, so exited children are reaped
+// rather than left behind as zombies, and their exit status is recorded. The most
+// recently exited processes are kept for listing.
+type Registry struct {
+	mu       sync.Mutex
+	running  map[int]*trackedProcess // Running processes by PID
+	finished []Process               // Exited processes, oldest first
+	wg       sync.WaitGroup
+}
+
+// trackedProcess is a running process and the handle to signal it
+type trackedProcess struct {
+	info    Process
+	process *os.Process
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{running: make(map[int]*trackedProcess)}
+}
+
+// track records a started process and waits for it in the background.
+// The returned channel receives the record of the process once it has exited.
+func (r *Registry) track(name string, cmd *exec.Cmd) <-chan Process {
+	tracked := &trackedProcess{
+		info: Process{
+			Name:    name,
+			PID:     cmd.Process.Pid,
+			Path:    cmd.Path,
+			Args:    append([]string(nil), cmd.Args[1:]...),
+			Started: time.Now(),
+		},
+		process: cmd.Process,
+	}
+
+	r.mu.Lock()
+	r.running[tracked.info.PID] = tracked
+	r.mu.Unlock()
+
+	done := make(chan Process, 1)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		err := cmd.Wait()
+		done <- r.exited(tracked, cmd.ProcessState, err)
+		close(done)
+	}()
+	return done
+}
+
+// exited records how a process ended and returns the final record
+func (r *Registry) exited(tracked *trackedProcess, state *os.ProcessState, err error) Process {
+	info := tracked.info
+	info.Ended = time.Now()
+	info.Code = -1
+	switch {
+	case state != nil:
+		info.Status = state.String()
+		info.Code = state.ExitCode()
+	case err != nil:
+		info.Status = err.Error()
+	}
+	logger.Info("Process %d of command '%s' exited after %s: %s", info.PID, info.Name, info.Duration(info.Ended).Round(time.Millisecond), info.Status)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, info.PID)
+	r.finished = append(r.finished, info)
+	if len(r.finished) > maxFinished {
+		r.finished = append([]Process(nil), r.finished[len(r.finished)-maxFinished:]...)
+	}
+	return info
+}
+
+// List returns the running processes followed by the recently exited ones, each
+// group ordered by start time
+func (r *Registry) List() []Process {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	running := make([]Process, 0, len(r.running))
+	for _, tracked := range r.running {
+		running = append(running, tracked.info)
+	}
+	sort.Slice(running, func(i, j int) bool {
+		if !running[i].Started.Equal(running[j].Started) {
+			return running[i].Started.Before(running[j].Started)
+		}
+		return running[i].PID < running[j].PID
+	})
+
+	finished := append([]Process(nil), r.finished...)
+	sort.SliceStable(finished, func(i, j int) bool { return finished[i].Started.Before(finished[j].Started) })
+	return append(running, finished...)
+}
+
+// newest returns the most recently started running process of a command
+func (r *Registry) newest(name string) (Process, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var newest Process
+	found := false
+	for _, tracked := range r.running {
+		info := tracked.info
+		if info.Name == name && (!found || info.Started.After(newest.Started) || info.Started.Equal(newest.Started) && info.PID > newest.PID) {
+			newest = info
+			found = true
+		}
+	}
+	return newest, found
+}
+
+// Kill asks the running processes matching target to terminate and returns them.
+// target is either a PID or a command name; only processes started by the executor
+// can be killed. On Unix the processes receive SIGTERM, on Windows they are killed.
+func (r *Registry) Kill(target string) ([]Process, error) {
+	r.mu.Lock()
+	var matches []*trackedProcess
+	pid, err := strconv.Atoi(target)
+	for _, tracked := range r.running {
+		if err == nil && tracked.info.PID == pid || tracked.info.Name == target {
+			matches = append(matches, tracked)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(matches) == 0 {
+		if err == nil {
+			return nil, fmt.Errorf("no running process with PID %d was started by the launcher", pid)
+		}
+		return nil, fmt.Errorf("no running process of command '%s'", target)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].info.PID < matches[j].info.PID })
+
+	killed := make([]Process, 0, len(matches))
+	for _, tracked := range matches {
+		if err := terminate(tracked.process); err != nil {
+			// The process may have exited in the meantime
+			logger.Warn("Failed to kill process %d of command '%s': %v", tracked.info.PID, tracked.info.Name, err)
+			continue
+		}
+		logger.Info("Killed process %d of command '%s'", tracked.info.PID, tracked.info.Name)
+		killed = append(killed, tracked.info)
+	}
+	if len(killed) == 0 {
+		return nil, fmt.Errorf("failed to kill '%s': the process has already exited", target)
+	}
+	return killed, nil
+}
+
+// Wait blocks until every tracked process has exited
+func (r *Registry) Wait() {
+	r.wg.Wait()
+}
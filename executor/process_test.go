@@ -0,0 +1,213 @@
+package executor
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"app-launcher/config"
+)
+
+// waitForExit polls the executor until a process of the command has exited and returns it
+func waitForExit(t *testing.T, executor *Executor, name string) Process {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, p := range executor.Processes() {
+			if p.Name == name && !p.Running() {
+				return p
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Process of '%s' did not exit", name)
+	return Process{}
+}
+
+// TestRegistryRecordsExitStatus tests that launched processes are reaped and their
+// exit status and duration recorded
+func TestRegistryRecordsExitStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"fail": {Path: "/bin/sh", Args: []string{"-c", "exit 3"}},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+	if err := executor.Execute("fail"); err != nil {
+		t.Fatalf("Failed to launch: %v", err)
+	}
+
+	p := waitForExit(t, executor, "fail")
+	if p.PID <= 0 || p.Code != 3 || p.Status != "exit status 3" {
+		t.Errorf("Unexpected exit record: %+v", p)
+	}
+	if strings.Join(p.Args, " ") != "-c exit 3" || p.Started.IsZero() || p.Duration(time.Now()) != p.Ended.Sub(p.Started) {
+		t.Errorf("Unexpected process details: %+v", p)
+	}
+}
+
+// TestKillByNameAndPID tests that running processes can be killed by command name,
+// alias or PID, and that unknown targets are rejected
+func TestKillByNameAndPID(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sleep")
+	}
+
+	cm := &aliasConfig{
+		MockConfigManager: MockConfigManager{
+			Data: config.Config{
+				Commands: map[string]config.Command{
+					"sleep": {Path: "/bin/sleep", Args: []string{"30"}},
+				},
+			},
+		},
+		aliases: map[string]string{"zz": "sleep"},
+	}
+	executor := NewExecutor(cm)
+	for i := 0; i < 2; i++ {
+		if err := executor.Execute("sleep"); err != nil {
+			t.Fatalf("Failed to launch: %v", err)
+		}
+	}
+
+	processes := executor.Processes()
+	if len(processes) != 2 || !processes[0].Running() || !processes[1].Running() {
+		t.Fatalf("Expected two running processes, got %+v", processes)
+	}
+
+	killed, err := executor.Kill(strconv.Itoa(processes[1].PID))
+	if err != nil || len(killed) != 1 || killed[0].PID != processes[1].PID {
+		t.Fatalf("Expected to kill PID %d, got %+v, %v", processes[1].PID, killed, err)
+	}
+	if p := waitForExit(t, executor, "sleep"); p.PID != processes[1].PID {
+		t.Fatalf("Expected PID %d to exit, got %+v", processes[1].PID, p)
+	}
+
+	killed, err = executor.Kill("zz")
+	if err != nil || len(killed) != 1 || killed[0].PID != processes[0].PID {
+		t.Fatalf("Expected to kill the remaining process by alias, got %+v, %v", killed, err)
+	}
+
+	executor.processes.Wait()
+	for _, p := range executor.Processes() {
+		if p.Running() || p.Code != -1 || p.Status != "signal: terminated" {
+			t.Errorf("Expected process to be terminated, got %+v", p)
+		}
+	}
+
+	if _, err := executor.Kill("sleep"); err == nil || !strings.Contains(err.Error(), "no running process") {
+		t.Errorf("Expected an error without running processes, got: %v", err)
+	}
+	if _, err := executor.Kill("1"); err == nil || !strings.Contains(err.Error(), "was started by the launcher") {
+		t.Errorf("Expected PIDs not started by the launcher to be refused, got: %v", err)
+	}
+}
+
+// TestSingleInstancePolicies tests that a command with a running process is not
+// launched again and that each policy does what it says instead
+func TestSingleInstancePolicies(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sleep and /bin/sh")
+	}
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"ignore":   {Path: "/bin/sleep", Args: []string{"30"}, SingleInstance: config.SingleInstanceIgnore},
+				"notify":   {Path: "/bin/sleep", Args: []string{"30"}, SingleInstance: config.SingleInstanceNotify},
+				"activate": {Path: "/bin/sleep", Args: []string{"30"}, AllowArgs: true, SingleInstance: config.SingleInstanceActivate, Activate: "focus"},
+				"focus":    {Path: "/bin/sh", Args: []string{"-c", "echo {pid} {query} > " + pidFile}, AllowArgs: true},
+			},
+		},
+	}
+	recorder := &mockRecorder{}
+	executor := NewExecutor(cm)
+	executor.SetRecorder(recorder)
+	defer func() {
+		for _, name := range []string{"ignore", "notify", "activate"} {
+			executor.Kill(name)
+		}
+		executor.processes.Wait()
+	}()
+
+	for _, name := range []string{"ignore", "notify", "activate"} {
+		if err := executor.Execute(name); err != nil {
+			t.Fatalf("Failed to launch '%s': %v", name, err)
+		}
+	}
+	pids := make(map[string]int)
+	for _, p := range executor.Processes() {
+		pids[p.Name] = p.PID
+	}
+
+	if err := executor.Execute("ignore"); err != nil {
+		t.Errorf("Expected 'ignore' to do nothing, got: %v", err)
+	}
+
+	var running *AlreadyRunningError
+	if err := executor.Execute("notify"); !errors.As(err, &running) || running.Name != "notify" || running.PID != pids["notify"] {
+		t.Errorf("Expected an AlreadyRunningError for PID %d, got: %v", pids["notify"], err)
+	}
+
+	if err := executor.Execute("activate", "main.go"); err != nil {
+		t.Fatalf("Failed to activate: %v", err)
+	}
+	waitForExit(t, executor, "focus")
+	data, err := os.ReadFile(pidFile)
+	if expected := strconv.Itoa(pids["activate"]) + " main.go\n"; err != nil || string(data) != expected {
+		t.Errorf("Expected the activate command to get the running PID %q, got %q, %v", expected, data, err)
+	}
+
+	count := 0
+	for _, p := range executor.Processes() {
+		if p.Running() {
+			count++
+		}
+	}
+	if count != 3 {
+		t.Errorf("Expected no second instances, got %d running processes", count)
+	}
+	// Ignoring a running command launches nothing, so it is not recorded
+	if got := strings.Join(recorder.names, " "); got != "ignore notify activate activate" {
+		t.Errorf("Unexpected recorded launches: %s", got)
+	}
+
+	// {pid} means nothing outside activation
+	if err := executor.Execute("focus"); err == nil || !strings.Contains(err.Error(), "only available to activate commands") {
+		t.Errorf("Expected {pid} to be refused outside activation, got: %v", err)
+	}
+
+	// Once the process has exited the command launches again
+	executor.Kill("ignore")
+	waitForExit(t, executor, "ignore")
+	if err := executor.Execute("ignore"); err != nil {
+		t.Fatalf("Failed to relaunch: %v", err)
+	}
+	if p, ok := executor.processes.newest("ignore"); !ok || p.PID == pids["ignore"] {
+		t.Errorf("Expected a new process after the first exited, got %+v", p)
+	}
+}
+
+// TestRegistryKeepsRecentExits tests that only the most recent exited processes are kept
+func TestRegistryKeepsRecentExits(t *testing.T) {
+	registry := NewRegistry()
+	for i := 0; i < maxFinished+5; i++ {
+		registry.exited(&trackedProcess{info: Process{Name: "p", PID: i + 1, Started: time.Now()}}, nil, nil)
+	}
+	processes := registry.List()
+	if len(processes) != maxFinished || processes[0].PID != 6 {
+		t.Errorf("Expected the last %d exits, got %d starting with PID %d", maxFinished, len(processes), processes[0].PID)
+	}
+}
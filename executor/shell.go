@@ -0,0 +1,326 @@
+package executor
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+
+	"app-launcher/config"
+)
+
+// shellSyntax is the quoting syntax of an interpreter
+type shellSyntax int
+
+const (
+	posixSyntax      shellSyntax = iota // sh, bash, zsh and other POSIX shells
+	fishSyntax                          // fish
+	cmdSyntax                           // cmd.exe
+	powerShellSyntax                    // PowerShell (pwsh, powershell.exe)
+)
+
+// defaultInterpreter returns the interpreter of shell commands that do not name one
+func defaultInterpreter() []string {
+	if runtime.GOOS == "windows" {
+		return []string{"cmd.exe", "/C"}
+	}
+	return []string{"/bin/sh", "-c"}
+}
+
+// syntaxOf returns the quoting syntax of an interpreter program, judging by its name.
+// Unknown programs are assumed to be POSIX shells.
+func syntaxOf(program string) shellSyntax {
+	base := strings.ToLower(filepath.Base(strings.ReplaceAll(program, `\`, "/")))
+	switch strings.TrimSuffix(base, ".exe") {
+	case "cmd":
+		return cmdSyntax
+	case "pwsh", "powershell":
+		return powerShellSyntax
+	case "fish":
+		return fishSyntax
+	}
+	return posixSyntax
+}
+
+// quote returns value as a single word that the shell takes literally
+func (s shellSyntax) quote(value string) (string, error) {
+	if s == cmdSyntax {
+		quoted, err := s.quoteDouble(value)
+		return `"` + quoted + `"`, err
+	}
+	return "'" + s.quoteSingle(value) + "'", nil
+}
+
+// quoteSingle escapes value for the inside of single quotes. cmd.exe has none.
+func (s shellSyntax) quoteSingle(value string) string {
+	switch s {
+	case powerShellSyntax:
+		// PowerShell also takes typographic single quotes for quotes
+		var out strings.Builder
+		for _, r := range value {
+			if strings.ContainsRune(psSingleQuotes, r) {
+				out.WriteRune(r)
+			}
+			out.WriteRune(r)
+		}
+		return out.String()
+	case fishSyntax:
+		return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	}
+	return strings.ReplaceAll(value, "'", `'\''`)
+}
+
+// quoteDouble escapes value for the inside of double quotes
+func (s shellSyntax) quoteDouble(value string) (string, error) {
+	switch s {
+	case cmdSyntax:
+		// Inside double quotes cmd.exe only treats " and % specially, and neither can be escaped there
+		if strings.ContainsAny(value, "\"%\r\n") {
+			return "", fmt.Errorf("%q cannot be quoted safely for cmd.exe", value)
+		}
+		return value, nil
+	case powerShellSyntax:
+		var out strings.Builder
+		for _, r := range value {
+			if r == '`' || r == '$' || strings.ContainsRune(psDoubleQuotes, r) {
+				out.WriteByte('`')
+			}
+			out.WriteRune(r)
+		}
+		return out.String(), nil
+	case fishSyntax:
+		return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`).Replace(value), nil
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace(value), nil
+}
+
+// Quote characters of PowerShell, which takes typographic quotes as well
+const (
+	psSingleQuotes = "'‘’‚‛"
+	psDoubleQuotes = `"“”„`
+)
+
+// quoteContext is the kind of text a value is inserted into
+type quoteContext int
+
+const (
+	unquoted     quoteContext = iota
+	singleQuoted              // '...'
+	doubleQuoted              // "..."
+	backquoted                // `...`, the old form of command substitution in POSIX shells
+)
+
+// lineQuoter follows the quoting of a shell command line while it is written out, so
+// that values are quoted for the place they are inserted at: a value inside "..." is
+// escaped rather than put in single quotes, which are literal text there. It knows
+// quotes, escapes, command substitutions and comments, which covers command lines
+// written by hand. Places where no quoting is safe, such as here-documents, are refused.
+type lineQuoter struct {
+	syntax    shellSyntax
+	contexts  []quoteContext // Innermost last; the outermost is unquoted
+	escaped   bool           // The previous character escapes the next one
+	dollar    bool           // The previous character is an unescaped $
+	comment   bool           // Inside a comment, which ends with the line
+	wordStart bool           // The next character starts a word
+	angles    int            // Unquoted < characters just written
+	heredoc   bool           // A here-document has started
+}
+
+// newLineQuoter creates a lineQuoter at the start of a command line
+func newLineQuoter(syntax shellSyntax) *lineQuoter {
+	return &lineQuoter{syntax: syntax, contexts: []quoteContext{unquoted}, wordStart: true}
+}
+
+// context returns the innermost quoting context
+func (q *lineQuoter) context() quoteContext {
+	return q.contexts[len(q.contexts)-1]
+}
+
+// write advances over literal text of the command line
+func (q *lineQuoter) write(text string) {
+	for _, r := range text {
+		q.step(r)
+	}
+}
+
+// step advances over a single character
+func (q *lineQuoter) step(r rune) {
+	dollar, wordStart := q.dollar, q.wordStart
+	q.dollar, q.wordStart = false, false
+	if r != '<' {
+		if q.angles == 2 && q.syntax != cmdSyntax {
+			q.heredoc = true
+		}
+		q.angles = 0
+	}
+
+	switch {
+	case q.escaped:
+		q.escaped = false
+	case q.comment:
+		q.comment = r != '\n'
+		q.wordStart = r == '\n'
+
+	case q.context() == singleQuoted:
+		switch {
+		case r == '\\' && q.syntax == fishSyntax:
+			q.escaped = true
+		case r == '\'' || q.syntax == powerShellSyntax && strings.ContainsRune(psSingleQuotes, r):
+			q.pop()
+		}
+
+	case q.context() == backquoted:
+		switch r {
+		case '\\':
+			q.escaped = true
+		case '`':
+			q.pop()
+		}
+
+	case q.context() == doubleQuoted:
+		switch {
+		case q.isEscape(r):
+			q.escaped = true
+		case r == '"' || q.syntax == powerShellSyntax && strings.ContainsRune(psDoubleQuotes, r):
+			q.pop()
+		case r == '`' && q.syntax == posixSyntax:
+			q.contexts = append(q.contexts, backquoted)
+		case r == '$':
+			q.dollar = true
+		case r == '(' && dollar:
+			// Command substitution, which is unquoted again
+			q.contexts = append(q.contexts, unquoted)
+		}
+
+	case q.syntax == cmdSyntax:
+		switch r {
+		case '^':
+			q.escaped = true
+		case '"':
+			q.contexts = append(q.contexts, doubleQuoted)
+		}
+
+	default:
+		switch {
+		case q.isEscape(r):
+			q.escaped = true
+		case r == '#' && wordStart:
+			q.comment = true
+		case r == '\'' || q.syntax == powerShellSyntax && strings.ContainsRune(psSingleQuotes, r):
+			q.contexts = append(q.contexts, singleQuoted)
+		case r == '"' || q.syntax == powerShellSyntax && strings.ContainsRune(psDoubleQuotes, r):
+			q.contexts = append(q.contexts, doubleQuoted)
+		case r == '`' && q.syntax == posixSyntax:
+			q.contexts = append(q.contexts, backquoted)
+		case r == '$':
+			q.dollar = true
+		case r == '(':
+			q.contexts = append(q.contexts, unquoted)
+		case r == ')':
+			q.pop()
+		case r == '<':
+			q.angles++
+		}
+		q.wordStart = unicode.IsSpace(r) || strings.ContainsRune(";&|()", r)
+	}
+}
+
+// isEscape reports whether r escapes the next character outside of single quotes
+func (q *lineQuoter) isEscape(r rune) bool {
+	if q.syntax == powerShellSyntax {
+		return r == '`'
+	}
+	return r == '\\'
+}
+
+// pop leaves the innermost context; the outermost one is never left
+func (q *lineQuoter) pop() {
+	if len(q.contexts) > 1 {
+		q.contexts = q.contexts[:len(q.contexts)-1]
+	}
+}
+
+// check returns why a value cannot be inserted at the current position, or ""
+func (q *lineQuoter) check() string {
+	switch {
+	case q.heredoc || q.angles == 2 && q.syntax != cmdSyntax:
+		return "cannot be used in a here-document"
+	case q.context() == backquoted:
+		return "cannot be used inside backquotes; use $(...) instead"
+	case q.escaped:
+		return "cannot follow an escape character"
+	case q.dollar && q.syntax != cmdSyntax:
+		return "cannot follow $"
+	}
+	return ""
+}
+
+// quote quotes value for the current position, which check has accepted, and
+// advances over it
+func (q *lineQuoter) quote(value string) (string, error) {
+	q.wordStart, q.angles = false, 0
+
+	switch q.context() {
+	case singleQuoted:
+		return q.syntax.quoteSingle(value), nil
+	case doubleQuoted:
+		return q.syntax.quoteDouble(value)
+	}
+	return q.syntax.quote(value)
+}
+
+// expandShell turns a shell command into the interpreter and its arguments, the last
+// of which is the command line. Placeholders in the command line are replaced by
+// values quoted for where they stand and user-typed arguments are appended quoted, so
+// neither can inject shell syntax. Other braces, environment variables and the rest of the command line
+// are left to the shell; environment variables in the interpreter are expanded.
+func (e *Executor) expandShell(cmd config.Command, extraArgs []string, pid int) (string, []string, error) {
+	interpreter := cmd.Interpreter
+	if len(interpreter) == 0 {
+		interpreter = defaultInterpreter()
+	}
+	lookup := variableLookup(cmd)
+	args := make([]string, 0, len(interpreter))
+	for _, arg := range interpreter {
+		expanded, err := expandString(arg, lookup, cmd.StrictEnv, false)
+		if err != nil {
+			return "", nil, fmt.Errorf("interpreter: %w", err)
+		}
+		args = append(args, expanded)
+	}
+	syntax := syntaxOf(args[0])
+
+	ctx := &templateContext{
+		args:      extraArgs,
+		now:       time.Now(),
+		clipboard: e.clipboard,
+		pid:       pid,
+		shell:     newLineQuoter(syntax),
+	}
+	line, err := ctx.expandLine(cmd.Shell)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Arguments consumed by placeholders are not appended again
+	if len(extraArgs) > 0 && !ctx.argsUsed {
+		if !cmd.AllowArgs {
+			return "", nil, fmt.Errorf("does not accept arguments")
+		}
+		for _, arg := range extraArgs {
+			ctx.shell.write(" ")
+			if problem := ctx.shell.check(); problem != "" {
+				return "", nil, fmt.Errorf("typed arguments %s", problem)
+			}
+			quoted, err := ctx.shell.quote(arg)
+			if err != nil {
+				return "", nil, err
+			}
+			line += " " + quoted
+		}
+	}
+
+	return args[0], append(args[1:], line), nil
+}
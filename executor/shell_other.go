@@ -0,0 +1,8 @@
+//go:build !windows
+
+package executor
+
+import "os/exec"
+
+// setCommandLine is only needed for cmd.exe on Windows
+func setCommandLine(execCmd *exec.Cmd, launch *Launch) {}
@@ -0,0 +1,223 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"app-launcher/config"
+)
+
+// TestShellQuote tests quoting for each shell syntax
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		program  string
+		value    string
+		expected string
+	}{
+		{"/bin/sh", "it's; rm -rf ~", `'it'\''s; rm -rf ~'`},
+		{"/usr/bin/bash", "$(id) `id`", "'$(id) `id`'"},
+		{"fish", `a\'b`, `'a\\\'b'`},
+		{`C:\Windows\System32\cmd.exe`, "a & b | c", `"a & b | c"`},
+		{"pwsh", "it's ‘x’; $env:PATH", "'it''s ‘‘x’’; $env:PATH'"},
+		{"PowerShell.exe", "", "''"},
+	}
+	for _, tt := range tests {
+		quoted, err := syntaxOf(tt.program).quote(tt.value)
+		if err != nil || quoted != tt.expected {
+			t.Errorf("quote(%q) for %s = %q, %v, expected %q", tt.value, tt.program, quoted, err, tt.expected)
+		}
+	}
+
+	for _, value := range []string{`say "hi"`, "%PATH%", "two\nlines"} {
+		if _, err := cmdSyntax.quote(value); err == nil {
+			t.Errorf("Expected %q to be refused for cmd.exe", value)
+		}
+	}
+}
+
+// TestResolveShellCommand tests that a shell command runs through its interpreter with
+// quoted placeholders and arguments
+func TestResolveShellCommand(t *testing.T) {
+	t.Setenv("LAUNCHER_TEST_SHELL", "/bin/bash")
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"grep":   {Shell: "grep -r {query} . | head -n {1}", Interpreter: []string{"$LAUNCHER_TEST_SHELL", "-c"}},
+				"append": {Shell: "ls $HOME | wc -l", AllowArgs: true},
+				"fixed":  {Shell: "uptime"},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	launch, err := executor.Resolve("grep", "5", "it's")
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	expected := []string{"-c", `grep -r '5' 'it'\''s' . | head -n '5'`}
+	if launch.Path != normalizePath("/bin/bash") || !reflect.DeepEqual(launch.Args, expected) {
+		t.Errorf("Unexpected launch: %s %q", launch.Path, launch.Args)
+	}
+
+	launch, err = executor.Resolve("append", "-a", "x y")
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if line := launch.Args[len(launch.Args)-1]; line != "ls $HOME | wc -l '-a' 'x y'" && line != `ls $HOME | wc -l "-a" "x y"` {
+		t.Errorf("Expected quoted arguments to be appended, got %q", line)
+	}
+
+	if _, err := executor.Resolve("fixed", "x"); err == nil || !strings.Contains(err.Error(), "does not accept arguments") {
+		t.Errorf("Expected arguments to be refused, got: %v", err)
+	}
+}
+
+// TestExecuteShellCommand tests that pipes and redirects work and that typed
+// arguments cannot inject commands
+func TestExecuteShellCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"echo": {Shell: "printf '%s\\n' {query} b | sort > " + out, Cwd: dir},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+	if err := executor.Execute("echo", "c", "a; touch pwned"); err != nil {
+		t.Fatalf("Failed to launch: %v", err)
+	}
+	if p := waitForExit(t, executor, "echo"); p.Code != 0 {
+		t.Fatalf("Shell command failed: %+v", p)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil || string(data) != "a; touch pwned\nb\nc\n" {
+		t.Errorf("Unexpected output %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("Typed argument was run as a command")
+	}
+}
+
+// TestShellBracesAreShellSyntax tests that braces around anything but a placeholder
+// reach the shell unchanged
+func TestShellBracesAreShellSyntax(t *testing.T) {
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"loop":    {Shell: `for f in *.txt; do echo "${f}"; done`},
+				"awk":     {Shell: `ls -l | awk '{if ($5 > 0) {print $9}}'`},
+				"find":    {Shell: `find . -name {1} -exec rm {} \;`},
+				"escaped": {Shell: `echo "${{1}}" {{query}}} {{x}}`},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"loop", nil, `for f in *.txt; do echo "${f}"; done`},
+		{"awk", nil, `ls -l | awk '{if ($5 > 0) {print $9}}'`},
+		{"find", []string{"*.tmp"}, `find . -name '*.tmp' -exec rm {} \;`},
+		{"escaped", nil, `echo "${1}" {query}} {{x}}`},
+	}
+	for _, tt := range tests {
+		launch, err := executor.Resolve(tt.name, tt.args...)
+		if err != nil {
+			t.Errorf("Failed to resolve '%s': %v", tt.name, err)
+			continue
+		}
+		line := launch.Args[len(launch.Args)-1]
+		if runtime.GOOS == "windows" {
+			line = strings.ReplaceAll(line, `"*.tmp"`, `'*.tmp'`)
+		}
+		if line != tt.expected {
+			t.Errorf("Expected '%s' to run %q, got %q", tt.name, tt.expected, line)
+		}
+	}
+}
+
+// TestShellQuotingContexts tests that values are quoted for the place they are
+// inserted at, and refused where no quoting is safe
+func TestShellQuotingContexts(t *testing.T) {
+	tests := []struct {
+		program  string
+		line     string
+		args     []string
+		expected string // Expected command line, or the error message if it starts with "!"
+	}{
+		{"sh", `echo "hello {1}"`, []string{"$(touch x) `id` \"q\" \\"}, `echo "hello \$(touch x) \` + "`id\\`" + ` \"q\" \\"`},
+		{"sh", `echo 'a {1}'`, []string{"it's"}, `echo 'a it'\''s'`},
+		{"sh", `echo "x" {1}`, []string{"a b"}, `echo "x" 'a b'`},
+		{"sh", `echo "$(basename {1})" "{2}"`, []string{"a b", "$HOME"}, `echo "$(basename 'a b')" "\$HOME"`},
+		{"sh", "# it's a comment\necho {query}", []string{"a", "b c"}, "# it's a comment\necho 'a' 'b c'"},
+		{"sh", `grep x <<< {1}`, []string{"a;b"}, `grep x <<< 'a;b'`},
+		{"sh", "echo `ls {1}`", []string{"a"}, "!placeholder {1} cannot be used inside backquotes"},
+		{"sh", `echo ${1}`, []string{"a"}, "!placeholder {1} cannot follow $"},
+		{"sh", `echo \{1}`, []string{"a"}, "!placeholder {1} cannot follow an escape character"},
+		{"sh", "cat <<EOF\n{1}\nEOF", []string{"a"}, "!placeholder {1} cannot be used in a here-document"},
+		{"fish", `echo "hi {1}" 'a {2}'`, []string{`$x"`, `it's\`}, `echo "hi \$x\"" 'a it\'s\\'`},
+		{"pwsh", `Write-Host "hi {1}" 'a {2}'`, []string{"$(calc)`\"", "it’s"}, "Write-Host \"hi `$(calc)```\"\" 'a it’’s'"},
+		{"cmd.exe", `echo "hi {1}" & dir {2}`, []string{"a&b", "c d"}, `echo "hi a&b" & dir "c d"`},
+		{"cmd.exe", `echo "{1}"`, []string{"50%"}, `!"50%" cannot be quoted safely for cmd.exe`},
+	}
+	for _, tt := range tests {
+		ctx := &templateContext{args: tt.args, shell: newLineQuoter(syntaxOf(tt.program))}
+		line, err := ctx.expandLine(tt.line)
+		if message, ok := strings.CutPrefix(tt.expected, "!"); ok {
+			if err == nil || !strings.Contains(err.Error(), message) {
+				t.Errorf("Expected %q for %s to fail with %q, got %q, %v", tt.line, tt.program, message, line, err)
+			}
+			continue
+		}
+		if err != nil || line != tt.expected {
+			t.Errorf("Expanding %q for %s = %q, %v, expected %q", tt.line, tt.program, line, err, tt.expected)
+		}
+	}
+}
+
+// TestExecuteShellCommandInsideDoubleQuotes tests that a typed argument inside double
+// quotes is not expanded by the shell
+func TestExecuteShellCommandInsideDoubleQuotes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Uses /bin/sh")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	cm := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"hello": {Shell: `echo "hello {1}" > ` + out, Cwd: dir},
+			},
+		},
+	}
+	executor := NewExecutor(cm)
+	if err := executor.Execute("hello", "$(touch pwned) `touch pwned` \"; touch pwned"); err != nil {
+		t.Fatalf("Failed to launch: %v", err)
+	}
+	if p := waitForExit(t, executor, "hello"); p.Code != 0 {
+		t.Fatalf("Shell command failed: %+v", p)
+	}
+
+	data, err := os.ReadFile(out)
+	if expected := "hello $(touch pwned) `touch pwned` \"; touch pwned\n"; err != nil || string(data) != expected {
+		t.Errorf("Expected %q, got %q, %v", expected, data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Error("Typed argument was run as a command")
+	}
+}
@@ -0,0 +1,25 @@
+//go:build windows
+
+package executor
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setCommandLine passes the command line of a shell command to cmd.exe as written.
+// Go would quote it for programs that parse their command line like the C runtime,
+// which cmd.exe does not, so the line is put in plain double quotes that cmd.exe
+// strips again.
+func setCommandLine(execCmd *exec.Cmd, launch *Launch) {
+	if launch.Command.Shell == "" || syntaxOf(launch.Path) != cmdSyntax || len(launch.Args) == 0 {
+		return
+	}
+	words := []string{syscall.EscapeArg(launch.Path)}
+	for _, arg := range launch.Args[:len(launch.Args)-1] {
+		words = append(words, syscall.EscapeArg(arg))
+	}
+	words = append(words, `"`+launch.Args[len(launch.Args)-1]+`"`)
+	execCmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: strings.Join(words, " ")}
+}
@@ -0,0 +1,262 @@
+package executor
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// templateContext holds the values available to placeholders while a command is launched.
+//
+// Supported placeholders in Path and Args:
+//   - {query}: all user-typed arguments joined with spaces
+//   - {1}, {2}, ...: a single user-typed argument by position
+//   - {home}: the current user's home directory
+//   - {env:NAME}: the value of the environment variable NAME
+//   - {date:LAYOUT}: the current time formatted with a Go time layout (e.g. {date:2006-01-02})
+//   - {clipboard}: the current text content of the clipboard
+//   - {pid}: the running process of the command an activate command is run for
+//
+// Use {{ and }} for literal braces. Braces that do not look like a placeholder
+// (for example Windows shell GUIDs such as ::{20D04FE0-3AEA-1069-A2D8-08002B30309D})
+// are kept as they are. In shell commands every value is quoted for the shell where it
+// is inserted, and only the placeholders above are replaced: other braces are shell
+// syntax (see expandLine).
+type templateContext struct {
+	args      []string
+	now       time.Time
+	clipboard func() string
+	pid       int         // Process being activated, 0 outside activation
+	shell     *lineQuoter // Quotes values in the command line of a shell command, nil otherwise
+
+	// argsUsed records whether any placeholder consumed the user-typed arguments
+	argsUsed bool
+}
+
+// expand replaces every placeholder in s with its value
+func (t *templateContext) expand(s string) (string, error) {
+	if !strings.ContainsAny(s, "{}") {
+		return s, nil
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "{{"):
+			out.WriteByte('{')
+			i++
+
+		case strings.HasPrefix(s[i:], "}}"):
+			out.WriteByte('}')
+			i++
+
+		case s[i] == '{':
+			end := strings.IndexByte(s[i:], '}')
+			if end < 0 {
+				out.WriteString(s[i:])
+				return out.String(), nil
+			}
+
+			name, param, ok := parsePlaceholder(s[i+1 : i+end])
+			if !ok {
+				// Not a placeholder, keep the brace literally
+				out.WriteByte('{')
+				continue
+			}
+
+			value, err := t.resolve(name, param)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(value)
+			i += end
+
+		default:
+			out.WriteByte(s[i])
+		}
+	}
+
+	return out.String(), nil
+}
+
+// expandLine replaces the placeholders in a shell command line. Braces around any
+// other name, as in ${f} or awk '{print $1}', are shell syntax and kept as they are,
+// as are {{ and }} unless they enclose a placeholder: {{query}} is a literal {query}.
+func (t *templateContext) expandLine(s string) (string, error) {
+	var out strings.Builder
+	literal := func(text string) {
+		out.WriteString(text)
+		t.shell.write(text)
+	}
+	for {
+		start := strings.IndexByte(s, '{')
+		if start < 0 {
+			literal(s)
+			return out.String(), nil
+		}
+		literal(s[:start])
+		s = s[start:]
+
+		escaped := strings.HasPrefix(s, "{{")
+		body := s[1:]
+		if escaped {
+			body = s[2:]
+		}
+		name, param, ok := "", "", false
+		end := strings.IndexByte(body, '}')
+		if end >= 0 {
+			name, param, ok = parsePlaceholder(body[:end])
+			ok = ok && knownPlaceholder(name)
+		}
+
+		switch {
+		case ok && escaped && strings.HasPrefix(body[end:], "}}"):
+			literal(s[1 : end+3])
+			s = body[end+2:]
+
+		case ok && !escaped:
+			if problem := t.shell.check(); problem != "" {
+				return "", fmt.Errorf("placeholder {%s} %s", body[:end], problem)
+			}
+			value, err := t.resolve(name, param)
+			if err != nil {
+				return "", err
+			}
+			out.WriteString(value)
+			s = body[end+1:]
+
+		default:
+			literal("{")
+			s = s[1:]
+		}
+	}
+}
+
+// knownPlaceholder reports whether name is one of the supported placeholders
+func knownPlaceholder(name string) bool {
+	if _, err := strconv.Atoi(name); err == nil {
+		return true
+	}
+	switch name {
+	case "query", "home", "env", "date", "clipboard", "pid":
+		return true
+	}
+	return false
+}
+
+// resolve returns the value of a single placeholder, quoted for the shell in shell
+// commands. There {query} becomes every argument quoted as a word of its own.
+func (t *templateContext) resolve(name, param string) (string, error) {
+	value, err := t.value(name, param)
+	if err != nil || t.shell == nil {
+		return value, err
+	}
+	if name != "query" {
+		return t.shell.quote(value)
+	}
+
+	words := make([]string, len(t.args))
+	for i, arg := range t.args {
+		if i > 0 {
+			t.shell.write(" ")
+		}
+		if words[i], err = t.shell.quote(arg); err != nil {
+			return "", err
+		}
+	}
+	return strings.Join(words, " "), nil
+}
+
+// value returns the value of a single placeholder
+func (t *templateContext) value(name, param string) (string, error) {
+	if n, err := strconv.Atoi(name); err == nil {
+		t.argsUsed = true
+		if n < 1 || n > len(t.args) {
+			return "", fmt.Errorf("placeholder {%s} requires at least %d argument(s), got %d", name, n, len(t.args))
+		}
+		return t.args[n-1], nil
+	}
+
+	switch name {
+	case "query":
+		t.argsUsed = true
+		if len(t.args) == 0 {
+			return "", fmt.Errorf("placeholder {query} requires arguments, but none were given")
+		}
+		return strings.Join(t.args, " "), nil
+
+	case "home":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("placeholder {home}: %w", err)
+		}
+		return home, nil
+
+	case "env":
+		if param == "" {
+			return "", fmt.Errorf("placeholder {env:NAME} requires a variable name")
+		}
+		value, ok := os.LookupEnv(param)
+		if !ok {
+			return "", fmt.Errorf("placeholder {env:%s}: environment variable is not set", param)
+		}
+		return value, nil
+
+	case "date":
+		if param == "" {
+			return "", fmt.Errorf("placeholder {date:LAYOUT} requires a layout, e.g. {date:2006-01-02}")
+		}
+		return t.now.Format(param), nil
+
+	case "clipboard":
+		if t.clipboard == nil {
+			return "", fmt.Errorf("placeholder {clipboard}: clipboard is not available")
+		}
+		value := t.clipboard()
+		if value == "" {
+			return "", fmt.Errorf("placeholder {clipboard}: clipboard is empty")
+		}
+		return value, nil
+
+	case "pid":
+		if t.pid == 0 {
+			return "", fmt.Errorf("placeholder {pid} is only available to activate commands")
+		}
+		return strconv.Itoa(t.pid), nil
+	}
+
+	if param != "" {
+		return "", fmt.Errorf("unknown placeholder {%s:%s}", name, param)
+	}
+	return "", fmt.Errorf("unknown placeholder {%s}", name)
+}
+
+// parsePlaceholder splits the text between braces into a name and an optional
+// parameter. The name must consist of lowercase letters or digits only.
+func parsePlaceholder(body string) (name, param string, ok bool) {
+	name, param, _ = strings.Cut(body, ":")
+	if name == "" {
+		return "", "", false
+	}
+
+	allDigits := true
+	allLower := true
+	for _, r := range name {
+		if r < '0' || r > '9' {
+			allDigits = false
+		}
+		if r < 'a' || r > 'z' {
+			allLower = false
+		}
+	}
+	if !allDigits && !allLower {
+		return "", "", false
+	}
+	if allDigits && param != "" {
+		return "", "", false
+	}
+
+	return name, param, true
+}
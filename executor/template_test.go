@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestTemplateExpand tests expansion of each supported placeholder
+func TestTemplateExpand(t *testing.T) {
+	t.Setenv("LAUNCHER_TEST_VAR", "value")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("No home directory available: %v", err)
+	}
+
+	ctx := &templateContext{
+		args:      []string{"first", "second word"},
+		now:       time.Date(2024, 3, 9, 14, 5, 0, 0, time.UTC),
+		clipboard: func() string { return "copied" },
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"https://github.com/search?q={query}", "https://github.com/search?q=first second word"},
+		{"{2}/{1}", "second word/first"},
+		{"{home}/bin", home + "/bin"},
+		{"{env:LAUNCHER_TEST_VAR}", "value"},
+		{"notes-{date:2006-01-02}.md", "notes-2024-03-09.md"},
+		{"{date:15:04}", "14:05"},
+		{"{clipboard}", "copied"},
+		{"{{literal}}", "{literal}"},
+		{"::{20D04FE0-3AEA-1069-A2D8-08002B30309D}", "::{20D04FE0-3AEA-1069-A2D8-08002B30309D}"},
+		{"unclosed {brace", "unclosed {brace"},
+	}
+
+	for _, tt := range tests {
+		result, err := ctx.expand(tt.input)
+		if err != nil {
+			t.Errorf("expand(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if result != tt.expected {
+			t.Errorf("expand(%q) = %q, expected %q", tt.input, result, tt.expected)
+		}
+	}
+}
+
+// TestTemplateExpandErrors tests that unknown placeholders and missing values are reported
+func TestTemplateExpandErrors(t *testing.T) {
+	os.Unsetenv("LAUNCHER_TEST_UNSET_VAR")
+
+	tests := []struct {
+		name  string
+		input string
+		ctx   *templateContext
+	}{
+		{"unknown placeholder", "{nope}", &templateContext{}},
+		{"unknown placeholder with parameter", "{nope:x}", &templateContext{}},
+		{"query without arguments", "{query}", &templateContext{}},
+		{"missing positional argument", "{2}", &templateContext{args: []string{"one"}}},
+		{"zero position", "{0}", &templateContext{args: []string{"one"}}},
+		{"unset environment variable", "{env:LAUNCHER_TEST_UNSET_VAR}", &templateContext{}},
+		{"env without name", "{env}", &templateContext{}},
+		{"date without layout", "{date}", &templateContext{}},
+		{"clipboard unavailable", "{clipboard}", &templateContext{}},
+		{"clipboard empty", "{clipboard}", &templateContext{clipboard: func() string { return "" }}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.ctx.expand(tt.input); err == nil {
+				t.Errorf("expand(%q) should fail", tt.input)
+			}
+		})
+	}
+}
+
+// TestTemplateArgsUsed tests that only argument placeholders mark arguments as consumed
+func TestTemplateArgsUsed(t *testing.T) {
+	ctx := &templateContext{args: []string{"a"}, now: time.Now()}
+	if _, err := ctx.expand("{home} {date:2006}"); err != nil {
+		t.Fatalf("expand returned error: %v", err)
+	}
+	if ctx.argsUsed {
+		t.Error("Arguments should not be marked as used without {query} or positional placeholders")
+	}
+
+	if _, err := ctx.expand("{1}"); err != nil {
+		t.Fatalf("expand returned error: %v", err)
+	}
+	if !ctx.argsUsed {
+		t.Error("Arguments should be marked as used after a positional placeholder")
+	}
+}
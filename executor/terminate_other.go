@@ -0,0 +1,13 @@
+//go:build !windows
+
+package executor
+
+import (
+	"os"
+	"syscall"
+)
+
+// terminate asks a process to exit with SIGTERM, so that it can clean up
+func terminate(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package executor
+
+import "os"
+
+// terminate ends a process. Windows has no signal a process can handle, so it is killed.
+func terminate(process *os.Process) error {
+	return process.Kill()
+}
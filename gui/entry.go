@@ -0,0 +1,30 @@
+package gui
+
+import (
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// commandEntry is a single-line entry that lets the GUIManager handle navigation
+// keys (Up, Down, Escape) before the default text editing behaviour.
+type commandEntry struct {
+	widget.Entry
+
+	// onKey is called for every typed key; returning true marks the key as handled
+	onKey func(key *fyne.KeyEvent) bool
+}
+
+// newCommandEntry creates a commandEntry
+func newCommandEntry() *commandEntry {
+	entry := &commandEntry{}
+	entry.ExtendBaseWidget(entry)
+	return entry
+}
+
+// TypedKey offers the key to onKey first and falls back to the default entry behaviour
+func (e *commandEntry) TypedKey(key *fyne.KeyEvent) {
+	if e.onKey != nil && e.onKey(key) {
+		return
+	}
+	e.Entry.TypedKey(key)
+}
@@ -1,10 +1,14 @@
 package gui
 
 import (
+	"strings"
+
+	"app-launcher/config"
 	"app-launcher/executor"
 	"app-launcher/logger"
 
 	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
 	"fyne.io/fyne/v2/container"
 	"fyne.io/fyne/v2/widget"
 )
@@ -13,27 +17,141 @@ import (
 type GUIManager struct {
 	app        fyne.App
 	window     fyne.Window
-	entry      *widget.Entry
+	entry      *commandEntry
 	errorLabel *widget.Label
+	resultList *widget.List
 	executor   *executor.Executor
 	visible    bool
+
+	// outputPanel shows the captured output of commands with "output": "show" in
+	// place of the result list
+	outputLabel *widget.Label
+	outputPanel *container.Scroll
+
+	// results holds the commands matching the current input, best match first
+	results  []match
+	selected int
+
+	// frecency ranks frequently and recently launched commands higher
+	frecency FrecencyScorer
+}
+
+// FrecencyScorer provides a usage score per command for ranking results
+type FrecencyScorer interface {
+	Score(name string) float64
 }
 
 // NewGUIManager creates a new GUIManager with the specified executor
 func NewGUIManager(exec *executor.Executor, app fyne.App) *GUIManager {
-	entry := widget.NewEntry()
+	entry := newCommandEntry()
 	entry.SetPlaceHolder(("Enter command..."))
 
 	errorLabel := widget.NewLabel("")
 	errorLabel.Hide()
 
-	return &GUIManager{
-		app:        app,
-		executor:   exec,
-		visible:    false,
-		entry:      entry,
-		errorLabel: errorLabel,
+	// Let the {clipboard} placeholder read from the system clipboard
+	exec.SetClipboardReader(func() string {
+		return app.Clipboard().Content()
+	})
+
+	outputLabel := widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	outputLabel.Wrapping = fyne.TextWrapBreak
+	outputPanel := container.NewVScroll(outputLabel)
+	outputPanel.Hide()
+
+	g := &GUIManager{
+		app:         app,
+		executor:    exec,
+		visible:     false,
+		entry:       entry,
+		errorLabel:  errorLabel,
+		outputLabel: outputLabel,
+		outputPanel: outputPanel,
+	}
+
+	// Captured output arrives once the command has exited, after the window was hidden
+	exec.SetOutputHandler(func(out executor.Output) {
+		fyne.Do(func() {
+			g.handleOutput(out)
+		})
+	})
+
+	g.resultList = widget.NewList(
+		func() int {
+			return len(g.results)
+		},
+		newResultItem,
+		func(id widget.ListItemID, item fyne.CanvasObject) {
+			g.updateResultItem(g.results[id].name, item)
+		},
+	)
+	g.resultList.Hide()
+
+	return g
+}
+
+// Layout of a result row: icon, command name and description
+const (
+	resultIcon = iota
+	resultName
+	resultDescription
+)
+
+// resultIconSize is the size of the icon shown next to a result
+const resultIconSize = 24
+
+// newResultItem creates the template for a row of the result list
+func newResultItem() fyne.CanvasObject {
+	icon := canvas.NewImageFromFile("")
+	icon.FillMode = canvas.ImageFillContain
+	icon.SetMinSize(fyne.NewSize(resultIconSize, resultIconSize))
+
+	description := widget.NewLabel("")
+	description.Importance = widget.LowImportance
+	description.Truncation = fyne.TextTruncateEllipsis
+
+	return container.NewBorder(nil, nil, container.NewHBox(icon, widget.NewLabel("")), nil, description)
+}
+
+// updateResultItem fills a result row with the icon, name and description of a command
+func (g *GUIManager) updateResultItem(name string, item fyne.CanvasObject) {
+	row := item.(*fyne.Container)
+	var left *fyne.Container
+	var description *widget.Label
+	for _, object := range row.Objects {
+		switch object := object.(type) {
+		case *fyne.Container:
+			left = object
+		case *widget.Label:
+			description = object
+		}
 	}
+	icon := left.Objects[resultIcon].(*canvas.Image)
+
+	cmd, _ := g.executor.Command(name)
+	left.Objects[resultName].(*widget.Label).SetText(name)
+	description.SetText(cmd.Description)
+
+	if cmd.Icon == "" {
+		icon.Hide()
+		return
+	}
+	if icon.File != cmd.Icon {
+		icon.File = cmd.Icon
+		icon.Refresh()
+	}
+	icon.Show()
+}
+
+// commandTags returns the tags of a command for tag searches
+func (g *GUIManager) commandTags(name string) []string {
+	cmd, _ := g.executor.Command(name)
+	return cmd.Tags
+}
+
+// SetFrecency sets the scorer used to rank frequently used commands higher
+func (g *GUIManager) SetFrecency(scorer FrecencyScorer) {
+	g.frecency = scorer
 }
 
 // Initialize creates the Fyne window with text entry widget and configures it
@@ -48,24 +166,35 @@ func (g *GUIManager) Initialize() {
 		g.handleCommandSubmit(text)
 	}
 
-	// Set up key event handler for Escape
+	// Update the result list on every keystroke
+	g.entry.OnChanged = func(text string) {
+		g.updateResults(text)
+	}
+
+	// Navigation keys are handled while the entry has focus as well as when it does not
+	g.entry.onKey = g.handleKey
 	g.window.Canvas().SetOnTypedKey(func(key *fyne.KeyEvent) {
-		if key.Name == fyne.KeyEscape {
-			logger.Info("Escape key pressed, hiding window")
-			g.Hide()
-		}
+		g.handleKey(key)
 	})
 
-	// Create container with entry and error label
-	content := container.NewVBox(
-		g.entry,
-		g.errorLabel,
+	// Clicking a result selects it and returns focus to the entry
+	g.resultList.OnSelected = func(id widget.ListItemID) {
+		g.selected = id
+		g.window.Canvas().Focus(g.entry)
+	}
+
+	// Create container with entry and error label above the result list, which
+	// captured output replaces until the input changes
+	content := container.NewBorder(
+		container.NewVBox(g.entry, g.errorLabel),
+		nil, nil, nil,
+		container.NewStack(g.resultList, g.outputPanel),
 	)
 
 	g.window.SetContent(content)
 
 	// Configure window to be always on top and centered
-	g.window.Resize(fyne.NewSize(400, 100))
+	g.window.Resize(fyne.NewSize(400, 320))
 	g.window.CenterOnScreen()
 	g.window.SetFixedSize(true)
 
@@ -116,15 +245,156 @@ func (g *GUIManager) ShowError(message string) {
 	g.errorLabel.Show()
 }
 
-// handleCommandSubmit processes command submission when Enter is pressed
-func (g *GUIManager) handleCommandSubmit(commandName string) {
-	logger.Info("User submitted command: '%s'", commandName)
+// handleOutput presents the captured output of a command as its output mode asks.
+// A failure is shown in the window, except for notifications, which report it themselves.
+func (g *GUIManager) handleOutput(out executor.Output) {
+	err := out.Err()
+	switch out.Mode {
+	case config.OutputClipboard:
+		if err != nil {
+			g.Show()
+			g.ShowError(err.Error())
+			return
+		}
+		g.app.Clipboard().SetContent(strings.TrimRight(out.Stdout, "\r\n"))
+		logger.Info("Copied the output of '%s' to the clipboard", out.Name)
+
+	case config.OutputNotify:
+		title := out.Name
+		if err != nil {
+			title = err.Error()
+		}
+		g.app.SendNotification(fyne.NewNotification(title, out.Text()))
+
+	default:
+		g.Show()
+		g.showOutput(out.Text())
+		if err != nil {
+			g.ShowError(err.Error())
+		}
+	}
+}
+
+// showOutput shows text in place of the result list
+func (g *GUIManager) showOutput(text string) {
+	if text == "" {
+		text = "(no output)"
+	}
+	g.outputLabel.SetText(text)
+	g.resultList.Hide()
+	g.outputPanel.Show()
+	g.outputPanel.ScrollToTop()
+}
+
+// handleKey handles navigation keys and reports whether the key was consumed
+func (g *GUIManager) handleKey(key *fyne.KeyEvent) bool {
+	switch key.Name {
+	case fyne.KeyEscape:
+		logger.Info("Escape key pressed, hiding window")
+		g.Hide()
+		return true
+	case fyne.KeyDown:
+		g.moveSelection(1)
+		return true
+	case fyne.KeyUp:
+		g.moveSelection(-1)
+		return true
+	}
+	return false
+}
+
+// moveSelection moves the highlighted result by delta, stopping at either end
+func (g *GUIManager) moveSelection(delta int) {
+	if len(g.results) == 0 {
+		return
+	}
+
+	selected := g.selected + delta
+	if selected < 0 {
+		selected = 0
+	}
+	if selected >= len(g.results) {
+		selected = len(g.results) - 1
+	}
+	g.selectResult(selected)
+}
+
+// selectResult highlights the result at index id
+func (g *GUIManager) selectResult(id int) {
+	g.selected = id
+	g.resultList.Select(id)
+}
+
+// updateResults re-ranks the commands against the command name in the input.
+// Only the first word is matched, so typing arguments keeps the current results.
+func (g *GUIManager) updateResults(input string) {
+	g.outputPanel.Hide()
+
+	query := ""
+	if fields := strings.Fields(input); len(fields) > 0 {
+		query = fields[0]
+	}
+
+	var frecency func(string) float64
+	if g.frecency != nil {
+		frecency = g.frecency.Score
+	}
+
+	if tag, ok := strings.CutPrefix(query, "#"); ok {
+		g.results = rankByTag(tag, g.executor.CommandNames(), g.commandTags, frecency)
+	} else {
+		g.results = rankCommands(query, g.executor.CommandNames(), frecency)
+		// A full command name or alias always comes first, including names with
+		// spaces such as those of desktop entries
+		if name, ok := g.executor.CommandName(input); ok {
+			g.results = promote(g.results, name)
+		} else if name, ok := g.executor.CommandName(query); ok {
+			g.results = promote(g.results, name)
+		}
+	}
+	g.selected = 0
+
+	g.resultList.UnselectAll()
+	g.resultList.Refresh()
+	if len(g.results) == 0 {
+		g.resultList.Hide()
+		return
+	}
+	g.resultList.Show()
+	g.selectResult(0)
+}
+
+// handleCommandSubmit processes command submission when Enter is pressed.
+// The highlighted result (or, with no results, the first word of the input)
+// selects the command and the rest of the input is passed as arguments.
+func (g *GUIManager) handleCommandSubmit(input string) {
+	logger.Info("User submitted command: '%s'", input)
 
 	// Clear any previous error
 	g.errorLabel.Hide()
 
+	fields, err := executor.SplitArgs(input)
+	if err != nil {
+		logger.Error("Failed to parse input, showing error to user: %v", err)
+		g.ShowError(err.Error())
+		return
+	}
+
+	commandName := ""
+	if len(fields) > 0 {
+		commandName = fields[0]
+		fields = fields[1:]
+	}
+	if g.selected >= 0 && g.selected < len(g.results) {
+		commandName = g.results[g.selected].name
+	}
+	// A name with spaces typed in full is not followed by arguments
+	if name, ok := g.executor.CommandName(input); ok && name == commandName {
+		fields = nil
+	}
+
 	// Execute the command
-	err := g.executor.Execute(commandName)
+	err = g.executor.Execute(commandName, fields...)
 
 	if err != nil {
 		// Show error message and keep window visible
@@ -2,6 +2,7 @@ package gui
 
 import (
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 
@@ -24,10 +25,34 @@ func (m *MockConfigManager) GetCommand(name string) (config.Command, bool) {
 	return cmd, exists
 }
 
+func (m *MockConfigManager) CommandNames() []string {
+	names := make([]string, 0, len(m.Data.Commands))
+	for name := range m.Data.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (m *MockConfigManager) Load() error {
 	return nil
 }
 
+// aliasConfigManager is a MockConfigManager that also resolves aliases
+type aliasConfigManager struct {
+	MockConfigManager
+	aliases map[string]string
+}
+
+func (m *aliasConfigManager) CommandName(name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if command, ok := m.aliases[name]; ok {
+		return command, true
+	}
+	_, exists := m.Data.Commands[name]
+	return name, exists
+}
+
 // **Feature: app-launcher, Property 1: Hotkey toggles window visibility**
 // **Validates: Requirements 1.1, 1.3**
 // For any window visibility state, pressing the configured hotkey should toggle
@@ -676,6 +701,207 @@ func TestEnterKeyCommandSubmission(t *testing.T) {
 	})
 }
 
+// TestCommandSubmissionWithArguments tests that text after the command name is passed as arguments
+func TestCommandSubmissionWithArguments(t *testing.T) {
+	testApp := test.NewApp()
+
+	execPath := "/bin/echo"
+	if runtime.GOOS == "windows" {
+		execPath = "C:\\Windows\\System32\\hostname.exe"
+	}
+
+	mockCfg := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"open":   {Path: execPath, Args: []string{}, AllowArgs: true},
+				"strict": {Path: execPath, Args: []string{}},
+			},
+		},
+	}
+	exec := executor.NewExecutor(mockCfg)
+
+	t.Run("arguments accepted by command", func(t *testing.T) {
+		gui := NewGUIManager(exec, testApp)
+		gui.Initialize()
+		gui.Show()
+
+		gui.entry.OnSubmitted(`open "my dir" other`)
+
+		if gui.visible {
+			t.Errorf("Window should be hidden after launch with arguments, error: %s", gui.errorLabel.Text)
+		}
+	})
+
+	t.Run("arguments rejected by command", func(t *testing.T) {
+		gui := NewGUIManager(exec, testApp)
+		gui.Initialize()
+		gui.Show()
+
+		gui.entry.OnSubmitted("strict extra")
+
+		if !gui.visible {
+			t.Error("Window should remain visible when arguments are rejected")
+		}
+		if !strings.Contains(gui.errorLabel.Text, "does not accept arguments") {
+			t.Errorf("Error should explain that arguments are not accepted, got: %s", gui.errorLabel.Text)
+		}
+	})
+
+	t.Run("unterminated quote shows error", func(t *testing.T) {
+		gui := NewGUIManager(exec, testApp)
+		gui.Initialize()
+		gui.Show()
+
+		gui.entry.OnSubmitted(`open "my dir`)
+
+		if !gui.errorLabel.Visible() {
+			t.Error("Error label should be visible for unterminated quote")
+		}
+	})
+}
+
+// TestResultListFollowsInput tests that typing updates the ranked result list
+func TestResultListFollowsInput(t *testing.T) {
+	testApp := test.NewApp()
+	mockCfg := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"chrome":  {Path: "dummy_path", Args: []string{}},
+				"code":    {Path: "dummy_path", Args: []string{}},
+				"calc":    {Path: "dummy_path", Args: []string{}},
+				"notepad": {Path: "dummy_path", Args: []string{}},
+			},
+		},
+	}
+	exec := executor.NewExecutor(mockCfg)
+	gui := NewGUIManager(exec, testApp)
+	gui.Initialize()
+	gui.Show()
+
+	test.Type(gui.entry, "c")
+	if len(gui.results) != 3 {
+		t.Fatalf("Expected 3 results for 'c', got %v", gui.results)
+	}
+	if !gui.resultList.Visible() {
+		t.Error("Result list should be visible when there are matches")
+	}
+
+	test.Type(gui.entry, "od")
+	if len(gui.results) != 1 || gui.results[0].name != "code" {
+		t.Errorf("Expected only 'code' for 'cod', got %v", gui.results)
+	}
+
+	// Arguments after the command name do not change the results
+	test.Type(gui.entry, "e ~/src/project")
+	if len(gui.results) != 1 || gui.results[0].name != "code" {
+		t.Errorf("Expected results to ignore arguments, got %v", gui.results)
+	}
+
+	gui.entry.SetText("xyz")
+	if len(gui.results) != 0 {
+		t.Errorf("Expected no results for 'xyz', got %v", gui.results)
+	}
+	if gui.resultList.Visible() {
+		t.Error("Result list should be hidden when nothing matches")
+	}
+}
+
+// TestArrowKeysMoveSelection tests Up/Down navigation through the result list
+func TestArrowKeysMoveSelection(t *testing.T) {
+	testApp := test.NewApp()
+	mockCfg := &MockConfigManager{
+		Data: config.Config{
+			Commands: map[string]config.Command{
+				"app1": {Path: "dummy_path", Args: []string{}},
+				"app2": {Path: "dummy_path", Args: []string{}},
+				"app3": {Path: "dummy_path", Args: []string{}},
+			},
+		},
+	}
+	exec := executor.NewExecutor(mockCfg)
+	gui := NewGUIManager(exec, testApp)
+	gui.Initialize()
+	gui.Show()
+
+	test.Type(gui.entry, "app")
+	if gui.selected != 0 {
+		t.Fatalf("First result should be selected initially, got %d", gui.selected)
+	}
+
+	gui.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	gui.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	if gui.selected != 2 {
+		t.Errorf("Expected selection 2 after two Down presses, got %d", gui.selected)
+	}
+
+	// Selection stops at the last result
+	gui.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyDown})
+	if gui.selected != 2 {
+		t.Errorf("Selection should stay on the last result, got %d", gui.selected)
+	}
+
+	gui.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyUp})
+	if gui.selected != 1 {
+		t.Errorf("Expected selection 1 after Up, got %d", gui.selected)
+	}
+
+	// Enter launches the highlighted command, not the typed text
+	gui.entry.OnSubmitted(gui.entry.Text)
+	if !strings.Contains(gui.errorLabel.Text, "'app2'") {
+		t.Errorf("Expected highlighted command 'app2' to be executed, got error: %s", gui.errorLabel.Text)
+	}
+}
+
+// TestEscapeKeyInEntryHidesWindow tests that Escape hides the window while the entry has focus
+func TestEscapeKeyInEntryHidesWindow(t *testing.T) {
+	testApp := test.NewApp()
+	mockCfg := &MockConfigManager{
+		Data: config.Config{Commands: map[string]config.Command{}},
+	}
+	exec := executor.NewExecutor(mockCfg)
+	gui := NewGUIManager(exec, testApp)
+	gui.Initialize()
+	gui.Show()
+
+	gui.entry.TypedKey(&fyne.KeyEvent{Name: fyne.KeyEscape})
+	if gui.visible {
+		t.Error("Window should be hidden after Escape in the entry")
+	}
+}
+
+// TestCommandSubmissionByNameWithSpaces tests that an alias with spaces, such as the
+// name of a desktop entry, launches its command when typed in full
+func TestCommandSubmissionByNameWithSpaces(t *testing.T) {
+	testApp := test.NewApp()
+
+	execPath := "/bin/echo"
+	if runtime.GOOS == "windows" {
+		execPath = "C:\\Windows\\System32\\hostname.exe"
+	}
+	cfg := &aliasConfigManager{
+		MockConfigManager: MockConfigManager{
+			Data: config.Config{
+				Commands: map[string]config.Command{
+					"org.example.Editor": {Path: execPath, Args: []string{}},
+				},
+			},
+		},
+		aliases: map[string]string{"Text Editor": "org.example.Editor"},
+	}
+	gui := NewGUIManager(executor.NewExecutor(cfg), testApp)
+	gui.Initialize()
+	gui.Show()
+
+	test.Type(gui.entry, "Text Editor")
+	if len(gui.results) == 0 || gui.results[0].name != "org.example.Editor" {
+		t.Fatalf("Expected the command to be listed first, got %v", gui.results)
+	}
+	gui.entry.OnSubmitted("Text Editor")
+	if gui.visible {
+		t.Errorf("Expected the command to launch without arguments, error: %s", gui.errorLabel.Text)
+	}
+}
+
 // TestErrorMessageDisplay tests error message display
 func TestErrorMessageDisplay(t *testing.T) {
 	testApp := test.NewApp()
@@ -713,3 +939,44 @@ func TestErrorMessageDisplay(t *testing.T) {
 		t.Error("Error label should be hidden after Show()")
 	}
 }
+
+// TestOutputDisplay tests that captured output is shown in place of the result list,
+// copied to the clipboard or sent as a notification
+func TestOutputDisplay(t *testing.T) {
+	testApp := test.NewApp()
+	mockCfg := &MockConfigManager{
+		Data: config.Config{Commands: map[string]config.Command{}},
+	}
+	exec := executor.NewExecutor(mockCfg)
+	gui := NewGUIManager(exec, testApp)
+	gui.Initialize()
+
+	gui.handleOutput(executor.Output{Name: "status", Mode: config.OutputShow, Stdout: " M go.mod\n"})
+	if !gui.visible || !gui.outputPanel.Visible() || gui.resultList.Visible() {
+		t.Error("Expected the window to show the output panel in place of the result list")
+	}
+	if gui.outputLabel.Text != " M go.mod" {
+		t.Errorf("Unexpected output text %q", gui.outputLabel.Text)
+	}
+
+	// Typing replaces the output with results again
+	test.Type(gui.entry, "s")
+	if gui.outputPanel.Visible() {
+		t.Error("Expected the output panel to be hidden while typing")
+	}
+
+	gui.handleOutput(executor.Output{Name: "uuid", Mode: config.OutputClipboard, Stdout: "3f2a\n"})
+	if content := testApp.Clipboard().Content(); content != "3f2a" {
+		t.Errorf("Expected the output on the clipboard, got %q", content)
+	}
+
+	failed := executor.Output{Name: "uuid", Mode: config.OutputClipboard, Process: executor.Process{Code: 1, Status: "exit status 1"}}
+	gui.handleOutput(failed)
+	if !gui.errorLabel.Visible() || !strings.Contains(gui.errorLabel.Text, "exit status 1") {
+		t.Errorf("Expected the failure to be shown, got %q", gui.errorLabel.Text)
+	}
+
+	test.AssertNotificationSent(t, fyne.NewNotification("date", "Mon"), func() {
+		gui.handleOutput(executor.Output{Name: "date", Mode: config.OutputNotify, Stdout: "Mon\n"})
+	})
+}
@@ -0,0 +1,186 @@
+package gui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxResults limits how many matches are shown under the input field
+const maxResults = 8
+
+// Scoring weights for fuzzy matching
+const (
+	scoreExact       = 1000 // query equals the command name
+	scorePrefix      = 100  // command name starts with the query
+	scoreMatch       = 10   // every matched character
+	scoreConsecutive = 15   // matched character directly follows the previous match
+	scoreBoundary    = 20   // matched character starts a word (after -, _, ., space or a case change)
+	penaltyGap       = 1    // every skipped character between matches
+)
+
+// Frecency blending: every unit of frecency score (one fresh launch) adds
+// frecencyWeight points, up to maxFrecencyBoost, so that frequently used commands
+// outrank equally good matches but never an exact match.
+const (
+	frecencyWeight   = 15
+	maxFrecencyBoost = 150
+)
+
+// match is a command name that matched the query, with its score
+type match struct {
+	name  string
+	score int
+}
+
+// fuzzyScore scores name against query using case-insensitive subsequence matching.
+// It returns false if the characters of query do not all appear in name in order.
+// Higher scores are better; exact and prefix matches always rank above scattered ones.
+func fuzzyScore(query, name string) (int, bool) {
+	if query == "" {
+		return 0, false
+	}
+
+	q := []rune(strings.ToLower(query))
+	n := []rune(name)
+	lower := []rune(strings.ToLower(name))
+
+	score := 0
+	qi := 0
+	last := -1
+	for i := 0; i < len(lower) && qi < len(q); i++ {
+		if lower[i] != q[qi] {
+			continue
+		}
+
+		score += scoreMatch
+		if last >= 0 {
+			if i == last+1 {
+				score += scoreConsecutive
+			} else {
+				score -= (i - last - 1) * penaltyGap
+			}
+		}
+		if isWordStart(n, i) {
+			score += scoreBoundary
+		}
+
+		last = i
+		qi++
+	}
+
+	if qi < len(q) {
+		return 0, false
+	}
+
+	lowerName := string(lower)
+	lowerQuery := string(q)
+	switch {
+	case lowerName == lowerQuery:
+		score += scoreExact
+	case strings.HasPrefix(lowerName, lowerQuery):
+		score += scorePrefix
+	}
+
+	// Prefer shorter names when everything else is equal
+	score -= len(lower) - len(q)
+
+	return score, true
+}
+
+// isWordStart reports whether the rune at index i begins a word in name
+func isWordStart(name []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := name[i-1], name[i]
+	switch prev {
+	case '-', '_', '.', ' ', '/':
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
+
+// frecencyBoost converts a frecency score into ranking points
+func frecencyBoost(frecency float64) int {
+	boost := int(frecency * frecencyWeight)
+	if boost > maxFrecencyBoost {
+		return maxFrecencyBoost
+	}
+	return boost
+}
+
+// rankCommands returns the names matching query, best match first.
+// frecency, if not nil, returns the usage score of a command which is blended into the ranking.
+// Ties are broken alphabetically so the order is stable between keystrokes.
+func rankCommands(query string, names []string, frecency func(name string) float64) []match {
+	var matches []match
+	for _, name := range names {
+		if score, ok := fuzzyScore(query, name); ok {
+			if frecency != nil {
+				score += frecencyBoost(frecency(name))
+			}
+			matches = append(matches, match{name: name, score: score})
+		}
+	}
+	return sortMatches(matches)
+}
+
+// rankByTag returns the names with a tag starting with tag, best match first.
+// A tag equal to the query ranks above one that only starts with it; frecency and
+// the name break ties as in rankCommands. An empty tag lists every tagged command.
+func rankByTag(tag string, names []string, tags func(name string) []string, frecency func(name string) float64) []match {
+	tag = strings.ToLower(tag)
+	var matches []match
+	for _, name := range names {
+		best := -1
+		for _, candidate := range tags(name) {
+			candidate = strings.ToLower(candidate)
+			switch {
+			case candidate == tag:
+				best = max(best, scoreExact)
+			case strings.HasPrefix(candidate, tag):
+				best = max(best, scorePrefix)
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		if frecency != nil {
+			best += frecencyBoost(frecency(name))
+		}
+		matches = append(matches, match{name: name, score: best})
+	}
+	return sortMatches(matches)
+}
+
+// sortMatches orders matches by score, then alphabetically so the order is stable
+// between keystrokes, and keeps the best maxResults
+func sortMatches(matches []match) []match {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	if len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+	return matches
+}
+
+// promote moves name to the top of matches, adding it if the query did not match it,
+// e.g. because the query is an alias
+func promote(matches []match, name string) []match {
+	promoted := []match{{name: name, score: scoreExact}}
+	for _, m := range matches {
+		if m.name != name {
+			promoted = append(promoted, m)
+		}
+	}
+	if len(promoted) > maxResults {
+		promoted = promoted[:maxResults]
+	}
+	return promoted
+}
@@ -0,0 +1,165 @@
+package gui
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFuzzyScoreMatching tests which names match a query
+func TestFuzzyScoreMatching(t *testing.T) {
+	tests := []struct {
+		query   string
+		name    string
+		matches bool
+	}{
+		{"chrome", "chrome", true},
+		{"chr", "chrome", true},
+		{"crm", "chrome", true},
+		{"CHR", "chrome", true},
+		{"vsc", "VSCode", true},
+		{"mc", "chrome", false},
+		{"chromes", "chrome", false},
+		{"", "chrome", false},
+	}
+
+	for _, tt := range tests {
+		_, ok := fuzzyScore(tt.query, tt.name)
+		if ok != tt.matches {
+			t.Errorf("fuzzyScore(%q, %q) matched=%v, expected %v", tt.query, tt.name, ok, tt.matches)
+		}
+	}
+}
+
+// TestRankCommandsOrder tests that better matches are ranked first
+func TestRankCommandsOrder(t *testing.T) {
+	names := []string{"powershell", "paint", "vscode", "code", "calc", "visual-studio-code"}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"code", "code"},    // exact match beats longer names containing it
+		{"vsc", "vscode"},   // prefix match beats scattered match
+		{"vs", "vscode"},    // shorter prefix match first
+		{"vsco", "vscode"},  // consecutive characters beat word boundaries
+		{"p", "paint"},      // ties broken by length, then alphabetically
+		{"vcode", "vscode"}, // subsequence match
+		{"studio", "visual-studio-code"},
+	}
+
+	for _, tt := range tests {
+		matches := rankCommands(tt.query, names, nil)
+		if len(matches) == 0 {
+			t.Errorf("rankCommands(%q) returned no matches", tt.query)
+			continue
+		}
+		if matches[0].name != tt.expected {
+			t.Errorf("rankCommands(%q) top match = %q, expected %q (all: %v)", tt.query, matches[0].name, tt.expected, matches)
+		}
+	}
+}
+
+// TestRankCommandsLimit tests that the number of results is bounded
+func TestRankCommandsLimit(t *testing.T) {
+	var names []string
+	for i := 0; i < maxResults*2; i++ {
+		names = append(names, "app"+string(rune('a'+i)))
+	}
+
+	if matches := rankCommands("app", names, nil); len(matches) != maxResults {
+		t.Errorf("Expected %d results, got %d", maxResults, len(matches))
+	}
+	if matches := rankCommands("zzz", names, nil); len(matches) != 0 {
+		t.Errorf("Expected no results for non-matching query, got %v", matches)
+	}
+}
+
+// TestRankCommandsFrecency tests that usage frequency is blended into the ranking
+func TestRankCommandsFrecency(t *testing.T) {
+	names := []string{"calc", "chrome", "code"}
+	frecency := func(name string) float64 {
+		if name == "code" {
+			return 5
+		}
+		return 0
+	}
+
+	// Without history, the shorter name wins the tie
+	if matches := rankCommands("c", names, nil); matches[0].name != "calc" {
+		t.Errorf("Expected 'calc' first without history, got %v", matches)
+	}
+
+	// Frequently used commands move up
+	if matches := rankCommands("c", names, frecency); matches[0].name != "code" {
+		t.Errorf("Expected 'code' first with history, got %v", matches)
+	}
+
+	// An exact match still beats a frequently used command
+	if matches := rankCommands("calc", []string{"calc", "calculator"}, func(name string) float64 {
+		if name == "calculator" {
+			return 100
+		}
+		return 0
+	}); matches[0].name != "calc" {
+		t.Errorf("Expected exact match 'calc' first, got %v", matches)
+	}
+}
+
+// TestPromote tests moving an exactly typed command to the top
+func TestPromote(t *testing.T) {
+	matches := rankCommands("ch", []string{"chat", "chrome", "cheese"}, nil)
+
+	promoted := promote(matches, "chrome")
+	if len(promoted) != len(matches) || promoted[0].name != "chrome" {
+		t.Errorf("Expected chrome first without duplicates, got %v", promoted)
+	}
+
+	promoted = promote(matches, "browser")
+	if len(promoted) != len(matches)+1 || promoted[0].name != "browser" {
+		t.Errorf("Expected an unmatched command to be added first, got %v", promoted)
+	}
+
+	many := make([]match, maxResults)
+	if promoted := promote(many, "x"); len(promoted) != maxResults {
+		t.Errorf("Expected at most %d results, got %d", maxResults, len(promoted))
+	}
+}
+
+// TestRankByTag tests searching commands by tag
+func TestRankByTag(t *testing.T) {
+	tags := map[string][]string{
+		"vscode":  {"dev", "editor"},
+		"gitk":    {"devtools"},
+		"firefox": {"web"},
+		"vim":     {"Dev"},
+	}
+	names := []string{"firefox", "gitk", "vim", "vscode"}
+	lookup := func(name string) []string { return tags[name] }
+
+	results := rankByTag("dev", names, lookup, nil)
+	got := make([]string, 0, len(results))
+	for _, m := range results {
+		got = append(got, m.name)
+	}
+	expected := []string{"vim", "vscode", "gitk"}
+	if strings.Join(got, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if results := rankByTag("", names, lookup, nil); len(results) != 4 {
+		t.Errorf("Expected an empty tag to list every tagged command, got %v", results)
+	}
+	if results := rankByTag("mail", names, lookup, nil); len(results) != 0 {
+		t.Errorf("Expected no results for an unknown tag, got %v", results)
+	}
+
+	frecency := func(name string) float64 {
+		if name == "vscode" {
+			return 5
+		}
+		return 0
+	}
+	if results := rankByTag("dev", names, lookup, frecency); results[0].name != "vscode" {
+		t.Errorf("Expected frecency to break the tie, got %v", results)
+	}
+}
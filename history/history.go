@@ -0,0 +1,158 @@
+package history
+
+import (
+	"app-launcher/logger"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileName is the name of the launch history file stored next to the configuration file
+const FileName = "history.json"
+
+// maxEvents is the number of most recent launches kept per command
+const maxEvents = 20
+
+// halfLife is the age at which a launch counts half as much as a launch right now
+const halfLife = 7 * 24 * time.Hour
+
+// fileFormat is the on-disk representation of the launch history
+type fileFormat struct {
+	Version  int                `json:"version"`
+	Launches map[string][]int64 `json:"launches"` // Unix timestamps per command, oldest first
+}
+
+// Store records launch events per command and derives a frecency score from them.
+//
+// The store is persisted as JSON after every change. A missing or corrupted file
+// is not an error: the store starts empty and overwrites the file on the next launch.
+type Store struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	launches map[string][]time.Time
+}
+
+// DefaultPath returns the history file path for the given configuration file
+func DefaultPath(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), FileName)
+}
+
+// NewStore creates a Store backed by the file at path and loads any existing history
+func NewStore(path string) *Store {
+	s := &Store{
+		path:     path,
+		now:      time.Now,
+		launches: make(map[string][]time.Time),
+	}
+	s.load()
+	return s
+}
+
+// load reads the history file, starting empty if it is missing or unreadable
+func (s *Store) load() {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read launch history '%s', starting empty: %v", s.path, err)
+		}
+		return
+	}
+
+	var file fileFormat
+	if err := json.Unmarshal(data, &file); err != nil {
+		logger.Warn("Launch history '%s' is corrupted, starting empty: %v", s.path, err)
+		return
+	}
+
+	for name, stamps := range file.Launches {
+		events := make([]time.Time, 0, len(stamps))
+		for _, stamp := range stamps {
+			events = append(events, time.Unix(stamp, 0))
+		}
+		s.launches[name] = events
+	}
+	logger.Info("Loaded launch history for %d commands from: %s", len(s.launches), s.path)
+}
+
+// save writes the history to disk, replacing the old file atomically.
+// The caller must hold s.mu.
+func (s *Store) save() error {
+	file := fileFormat{
+		Version:  1,
+		Launches: make(map[string][]int64, len(s.launches)),
+	}
+	for name, events := range s.launches {
+		stamps := make([]int64, 0, len(events))
+		for _, event := range events {
+			stamps = append(stamps, event.Unix())
+		}
+		file.Launches[name] = stamps
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode launch history: %w", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write launch history: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write launch history: %w", err)
+	}
+	return nil
+}
+
+// Record adds a launch event for the named command and persists the history
+func (s *Store) Record(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := append(s.launches[name], s.now())
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	s.launches[name] = events
+
+	return s.save()
+}
+
+// Score returns the frecency score of the named command.
+// Every recorded launch contributes 1.0 when it is brand new, halving every halfLife.
+func (s *Store) Score(name string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	score := 0.0
+	for _, event := range s.launches[name] {
+		age := now.Sub(event)
+		if age < 0 {
+			age = 0
+		}
+		score += math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+	return score
+}
+
+// Reset removes all recorded launches of the named command and persists the history
+func (s *Store) Reset(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.launches[name]; !exists {
+		return fmt.Errorf("no launch history for command '%s'", name)
+	}
+	delete(s.launches, name)
+
+	logger.Info("Reset launch history for command '%s'", name)
+	return s.save()
+}
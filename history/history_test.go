@@ -0,0 +1,129 @@
+package history
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestStore creates a Store in a temporary directory with a controllable clock
+func newTestStore(t *testing.T, now *time.Time) (*Store, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	s := NewStore(path)
+	s.now = func() time.Time { return *now }
+	return s, path
+}
+
+// TestRecordPersistsAcrossRestarts tests that launches survive reopening the store
+func TestRecordPersistsAcrossRestarts(t *testing.T) {
+	now := time.Now()
+	s, path := newTestStore(t, &now)
+
+	for i := 0; i < 3; i++ {
+		if err := s.Record("chrome"); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if err := s.Record("code"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	reopened := NewStore(path)
+	reopened.now = func() time.Time { return now }
+
+	if score := reopened.Score("chrome"); math.Abs(score-3) > 0.01 {
+		t.Errorf("Expected score ~3 for chrome after reopening, got %f", score)
+	}
+	if score := reopened.Score("code"); math.Abs(score-1) > 0.01 {
+		t.Errorf("Expected score ~1 for code after reopening, got %f", score)
+	}
+	if score := reopened.Score("unknown"); score != 0 {
+		t.Errorf("Expected score 0 for unknown command, got %f", score)
+	}
+}
+
+// TestScoreDecaysWithAge tests that older launches count less
+func TestScoreDecaysWithAge(t *testing.T) {
+	now := time.Now()
+	s, _ := newTestStore(t, &now)
+
+	if err := s.Record("chrome"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	now = now.Add(halfLife)
+	if score := s.Score("chrome"); math.Abs(score-0.5) > 0.01 {
+		t.Errorf("Expected score ~0.5 after one half-life, got %f", score)
+	}
+
+	// A recent launch of another command outranks several old ones
+	if err := s.Record("code"); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if s.Score("code") <= s.Score("chrome") {
+		t.Errorf("Recent launch should score higher: code=%f chrome=%f", s.Score("code"), s.Score("chrome"))
+	}
+}
+
+// TestRecordKeepsBoundedHistory tests that only the most recent launches are kept
+func TestRecordKeepsBoundedHistory(t *testing.T) {
+	now := time.Now()
+	s, _ := newTestStore(t, &now)
+
+	for i := 0; i < maxEvents*2; i++ {
+		if err := s.Record("chrome"); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	if n := len(s.launches["chrome"]); n != maxEvents {
+		t.Errorf("Expected %d events, got %d", maxEvents, n)
+	}
+}
+
+// TestCorruptedHistoryStartsEmpty tests recovery from an unreadable history file
+func TestCorruptedHistoryStartsEmpty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("Failed to write history file: %v", err)
+	}
+
+	s := NewStore(path)
+	if score := s.Score("chrome"); score != 0 {
+		t.Errorf("Expected empty history from corrupted file, got score %f", score)
+	}
+
+	// Recording replaces the corrupted file with a valid one
+	if err := s.Record("chrome"); err != nil {
+		t.Fatalf("Record failed after corrupted file: %v", err)
+	}
+	if NewStore(path).Score("chrome") == 0 {
+		t.Error("History should be readable after recording over a corrupted file")
+	}
+}
+
+// TestResetCommand tests clearing the history of a single command
+func TestResetCommand(t *testing.T) {
+	now := time.Now()
+	s, path := newTestStore(t, &now)
+
+	s.Record("chrome")
+	s.Record("code")
+
+	if err := s.Reset("chrome"); err != nil {
+		t.Fatalf("Reset failed: %v", err)
+	}
+	if err := s.Reset("chrome"); err == nil {
+		t.Error("Resetting a command without history should fail")
+	}
+
+	reopened := NewStore(path)
+	if reopened.Score("chrome") != 0 {
+		t.Error("Reset command should have no history after reopening")
+	}
+	if reopened.Score("code") == 0 {
+		t.Error("Other commands should keep their history after reset")
+	}
+}
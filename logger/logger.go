@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"time"
@@ -28,6 +29,11 @@ func (l *Logger) logWithTimestamp(level, format string, v ...interface{}) {
 	l.logger.Printf("[%s] %s: %s", timestamp, level, message)
 }
 
+// SetOutput sets the destination for log messages
+func (l *Logger) SetOutput(w io.Writer) {
+	l.logger.SetOutput(w)
+}
+
 // Info logs an informational message
 func (l *Logger) Info(format string, v ...interface{}) {
 	l.logWithTimestamp("INFO", format, v...)
@@ -51,6 +57,11 @@ func (l *Logger) Fatal(format string, v ...interface{}) {
 
 // Package-level convenience functions using the default logger
 
+// SetOutput sets the destination of the default logger (stderr by default)
+func SetOutput(w io.Writer) {
+	defaultLogger.SetOutput(w)
+}
+
 // Info logs an informational message using the default logger
 func Info(format string, v ...interface{}) {
 	defaultLogger.Info(format, v...)
@@ -3,6 +3,7 @@ package logger
 import (
 	"bytes"
 	"log"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -105,6 +106,19 @@ func TestPackageLevelFunctions(t *testing.T) {
 	Warn("Package level warn test")
 }
 
+// TestSetOutput tests redirecting the default logger
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(os.Stderr)
+
+	Info("Redirected message")
+
+	if !strings.Contains(buf.String(), "INFO: Redirected message") {
+		t.Errorf("Expected redirected output, got: %s", buf.String())
+	}
+}
+
 // TestTimestampFormat tests that the timestamp is in the correct format
 func TestTimestampFormat(t *testing.T) {
 	var buf bytes.Buffer
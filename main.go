@@ -3,15 +3,19 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
-	"path/filepath"
+	"time"
 
 	"app-launcher/config"
+	"app-launcher/control"
 	"app-launcher/executor"
 	"app-launcher/gui"
+	"app-launcher/history"
 	"app-launcher/hotkey"
 	"app-launcher/logger"
 
+	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/dialog"
 )
@@ -22,6 +26,7 @@ type App struct {
 	executor *executor.Executor
 	gui      *gui.GUIManager
 	hotkey   *hotkey.HotkeyManager
+	control  *control.Server
 }
 
 // NewApp creates and initializes a new App with all components
@@ -45,27 +50,56 @@ func NewApp(configPath, hotkeyStr string) (*App, error) {
 
 	// Initialize Executor
 	exec := executor.NewExecutor(configManager)
+	exec.SetFallback(newPathCommands(configManager))
 	logger.Info("Executor initialized")
 
+	// Record launches next to the configuration file for frecency ranking
+	launchHistory := history.NewStore(history.DefaultPath(configPath))
+	exec.SetRecorder(launchHistory)
+
 	// Create Fyne application
 	fyneApp := app.New()
 
 	// Initialize GUIManager
 	guiManager := gui.NewGUIManager(exec, fyneApp)
+	guiManager.SetFrecency(launchHistory)
 	guiManager.Initialize()
 
+	// Failures after a launch, such as a failing step of a macro, bring the window
+	// back to report them
+	exec.SetErrorHandler(func(err error) {
+		fyne.Do(func() {
+			guiManager.Show()
+			guiManager.ShowError(err.Error())
+		})
+	})
+
+	// Reload the configuration whenever the file changes. A broken file keeps
+	// the previous commands active and the error is surfaced in the window.
+	if err := configManager.Watch(func(err error) {
+		if err != nil {
+			fyne.Do(func() {
+				guiManager.ShowError(fmt.Sprintf("Failed to reload configuration: %v", err))
+			})
+		}
+	}); err != nil {
+		logger.Warn("Configuration hot-reload disabled: %v", err)
+	}
+
 	// Initialize HotkeyManager with toggle callback
 	hotkeyManager, err := hotkey.NewHotkeyManager(func() {
 		guiManager.Toggle()
 	})
 	if err != nil {
 		logger.Error("Failed to create hotkey manager: %v", err)
+		configManager.Close()
 		return nil, fmt.Errorf("failed to create hotkey manager: %w", err)
 	}
 
 	// Register the hotkey
 	if err := hotkeyManager.Register(hotkeyStr); err != nil {
 		logger.Error("Failed to register hotkey: %v", err)
+		configManager.Close()
 		return nil, fmt.Errorf("failed to register hotkey: %w", err)
 	}
 
@@ -88,6 +122,16 @@ func (a *App) Run() error {
 		return fmt.Errorf("failed to start hotkey listener: %w", err)
 	}
 
+	// Start the control socket so other tools can drive the launcher.
+	// The launcher still works from the hotkey if this fails.
+	if server, err := control.NewServer(control.DefaultSocketPath(), a); err != nil {
+		logger.Warn("Control socket disabled: %v", err)
+	} else if err := server.Start(); err != nil {
+		logger.Warn("Control socket disabled: %v", err)
+	} else {
+		a.control = server
+	}
+
 	logger.Info("Application running, waiting for hotkey events")
 	// Run the GUI (this blocks until the app is closed)
 	a.gui.Run()
@@ -102,36 +146,111 @@ func (a *App) Shutdown() {
 	if a.hotkey != nil {
 		a.hotkey.Stop()
 	}
+	if a.control != nil {
+		a.control.Close()
+	}
+	if a.config != nil {
+		a.config.Close()
+	}
 	logger.Info("Shutdown complete")
 }
 
+// RunCommand executes a command on behalf of a control request
+func (a *App) RunCommand(name string, args []string) error {
+	var err error
+	fyne.DoAndWait(func() {
+		err = a.executor.Execute(name, args...)
+	})
+	return err
+}
+
+// Show shows the launcher window on behalf of a control request
+func (a *App) Show() {
+	fyne.Do(a.gui.Show)
+}
+
+// Hide hides the launcher window on behalf of a control request
+func (a *App) Hide() {
+	fyne.Do(a.gui.Hide)
+}
+
+// Toggle toggles the launcher window on behalf of a control request
+func (a *App) Toggle() {
+	fyne.Do(a.gui.Toggle)
+}
+
+// Reload reloads the configuration file on behalf of a control request
+func (a *App) Reload() error {
+	return a.config.Load()
+}
+
+// List returns the command names on behalf of a control request
+func (a *App) List() []string {
+	return a.executor.CommandNames()
+}
+
+// Processes returns the processes started by the launcher on behalf of a control request
+func (a *App) Processes() []control.Process {
+	return controlProcesses(a.executor.Processes(), time.Now())
+}
+
+// Kill terminates processes started by the launcher on behalf of a control request
+func (a *App) Kill(target string) ([]control.Process, error) {
+	killed, err := a.executor.Kill(target)
+	if err != nil {
+		return nil, err
+	}
+	return controlProcesses(killed, time.Now()), nil
+}
+
 // getDefaultConfigPath returns the default configuration file path
-// %APPDATA%\launcher\config.json on Windows
+// (see config.DefaultPath for the search order)
 func getDefaultConfigPath() string {
-	appData := os.Getenv("APPDATA")
-	if appData == "" {
-		// Fallback to current directory if APPDATA is not set
-		return "config.json"
-	}
-	return filepath.Join(appData, "launcher", "config.json")
+	return config.DefaultPath()
 }
 
 func main() {
 	// Parse command-line flags
 	//
 	// Available flags:
-	//   --config: Path to the JSON configuration file
-	//             Default: %APPDATA%\launcher\config.json
+	//   --config: Path to the configuration file (JSON, TOML or YAML)
+	//             Default: $LAUNCHER_CONFIG, else the first launcher/config.* found in
+	//             $XDG_CONFIG_HOME, $XDG_CONFIG_DIRS and (on Windows) %APPDATA%
 	//             Example: --config="C:\custom\config.json"
 	//
 	//   --hotkey: Global hotkey to activate the launcher
 	//             Default: Alt+Space
 	//             Supported formats: "Alt+Space", "Ctrl+Space", "Ctrl+Alt+L", etc.
 	//             Example: --hotkey="Ctrl+Alt+L"
+	//
+	//   --reset-stats: Forget the launch history of a command and exit
+	//             Example: --reset-stats=chrome
+	//
+	// Headless subcommands (see cli.go) run without opening the window:
+	//   launcher validate | list | run | which | ps | kill | convert | init
 	configPath := flag.String("config", getDefaultConfigPath(), "Path to configuration file")
 	hotkeyStr := flag.String("hotkey", "Alt+Space", "Hotkey to activate launcher (e.g., 'Ctrl+Space', 'Alt+Space')")
+	resetStats := flag.String("reset-stats", "", "Reset the launch history of the named command and exit")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: launcher [flags] [subcommand]")
+		flag.PrintDefaults()
+		runCLI(*configPath, nil, io.Discard, flag.CommandLine.Output())
+	}
 	flag.Parse()
 
+	if flag.NArg() > 0 {
+		os.Exit(runCLI(*configPath, flag.Args(), os.Stdout, os.Stderr))
+	}
+
+	if *resetStats != "" {
+		store := history.NewStore(history.DefaultPath(*configPath))
+		if err := store.Reset(*resetStats); err != nil {
+			logger.Fatal("Failed to reset launch history: %v", err)
+		}
+		fmt.Printf("Launch history of '%s' has been reset\n", *resetStats)
+		return
+	}
+
 	logger.Info("Application launcher starting")
 	logger.Info("Command-line arguments: config=%s, hotkey=%s", *configPath, *hotkeyStr)
 
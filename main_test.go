@@ -7,28 +7,33 @@ import (
 )
 
 func TestGetDefaultConfigPath(t *testing.T) {
-	// Save original APPDATA value
-	originalAppData := os.Getenv("APPDATA")
-	defer os.Setenv("APPDATA", originalAppData)
-
-	// Test with APPDATA set
-	testAppData := "C:\\Users\\TestUser\\AppData\\Roaming"
-	os.Setenv("APPDATA", testAppData)
-
-	expected := filepath.Join(testAppData, "launcher", "config.json")
-	result := getDefaultConfigPath()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	t.Setenv("XDG_CONFIG_DIRS", t.TempDir())
+
+	// Test with LAUNCHER_CONFIG set
+	t.Setenv("LAUNCHER_CONFIG", "/custom/launcher.toml")
+	if result := getDefaultConfigPath(); result != "/custom/launcher.toml" {
+		t.Errorf("Expected LAUNCHER_CONFIG to win, got %s", result)
+	}
 
-	if result != expected {
+	// Test without any configuration file: the default is in XDG_CONFIG_HOME
+	t.Setenv("LAUNCHER_CONFIG", "")
+	expected := filepath.Join(configHome, "launcher", "config.json")
+	if result := getDefaultConfigPath(); result != expected {
 		t.Errorf("Expected %s, got %s", expected, result)
 	}
 
-	// Test with APPDATA not set
-	os.Setenv("APPDATA", "")
-	result = getDefaultConfigPath()
-	expected = "config.json"
-
-	if result != expected {
-		t.Errorf("Expected %s when APPDATA is empty, got %s", expected, result)
+	// Test with an existing YAML configuration
+	expected = filepath.Join(configHome, "launcher", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(expected), 0755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	if err := os.WriteFile(expected, []byte("commands: {}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if result := getDefaultConfigPath(); result != expected {
+		t.Errorf("Expected %s, got %s", expected, result)
 	}
 }
 
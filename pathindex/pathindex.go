@@ -0,0 +1,169 @@
+// Package pathindex offers the executables found in the directories of $PATH as commands.
+package pathindex
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"app-launcher/config"
+	"app-launcher/logger"
+)
+
+// Index maps executable names to their paths in the directories of a PATH list.
+//
+// The directories are scanned lazily on first use and again whenever the
+// modification time of one of them changes, which happens when files are added,
+// removed or renamed. A name found in several directories resolves to the first
+// one, as a shell would.
+type Index struct {
+	dirs []string
+
+	mu       sync.Mutex
+	mtimes   map[string]time.Time // Modification time of each directory at the last scan
+	scanned  bool
+	commands map[string]string // Executable name to path
+	names    []string          // Sorted executable names
+}
+
+// New creates an Index for a PATH list such as os.Getenv("PATH").
+// Empty and relative entries are ignored so that the result does not depend on the
+// launcher's working directory.
+func New(pathList string) *Index {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || !filepath.IsAbs(dir) {
+			continue
+		}
+		dir = filepath.Clean(dir)
+		if !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	return &Index{dirs: dirs}
+}
+
+// Lookup returns the path of the executable with the given name
+func (x *Index) Lookup(name string) (string, bool) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+	path, ok := x.commands[name]
+	return path, ok
+}
+
+// Names returns the names of all executables in sorted order
+func (x *Index) Names() []string {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.refresh()
+	return append([]string(nil), x.names...)
+}
+
+// GetCommand returns a command running the named executable. Arguments typed after
+// the name are passed on.
+func (x *Index) GetCommand(name string) (config.Command, bool) {
+	path, ok := x.Lookup(name)
+	if !ok {
+		return config.Command{}, false
+	}
+	// The path is taken literally, so braces must not be taken for placeholders, nor
+	// $ and % signs for environment variables
+	path = strings.NewReplacer("{", "{{", "}", "}}", "$", "$$", "%", "%%").Replace(path)
+	return config.Command{Path: path, Args: []string{}, AllowArgs: true}, true
+}
+
+// CommandNames returns the names of all executables in sorted order
+func (x *Index) CommandNames() []string {
+	return x.Names()
+}
+
+// refresh rescans the directories if any of them changed since the last scan.
+// The caller must hold x.mu.
+func (x *Index) refresh() {
+	mtimes := make(map[string]time.Time, len(x.dirs))
+	changed := !x.scanned
+	for _, dir := range x.dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			// A missing directory has the zero time, so it is noticed when it appears
+			continue
+		}
+		mtimes[dir] = info.ModTime()
+		if !info.ModTime().Equal(x.mtimes[dir]) {
+			changed = true
+		}
+	}
+	if len(mtimes) != len(x.mtimes) {
+		changed = true
+	}
+	if !changed {
+		return
+	}
+
+	commands := make(map[string]string)
+	for _, dir := range x.dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logger.Warn("Cannot scan PATH directory '%s': %v", dir, err)
+			}
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := executableName(entry.Name())
+			if !ok || commands[name] != "" {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				commands[name] = path
+			}
+		}
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	x.mtimes, x.commands, x.names, x.scanned = mtimes, commands, names, true
+	logger.Info("Indexed %d executables from %d PATH directories", len(names), len(x.dirs))
+}
+
+// executableName returns the command name for a file name. On Windows only files with
+// an extension listed in %PATHEXT% are executables, and the extension is dropped.
+func executableName(fileName string) (string, bool) {
+	if runtime.GOOS != "windows" {
+		return fileName, !strings.HasPrefix(fileName, ".")
+	}
+
+	pathext := os.Getenv("PATHEXT")
+	if pathext == "" {
+		pathext = ".com;.exe;.bat;.cmd"
+	}
+	ext := filepath.Ext(fileName)
+	for _, candidate := range strings.Split(pathext, ";") {
+		if candidate != "" && strings.EqualFold(ext, candidate) {
+			return strings.TrimSuffix(fileName, ext), true
+		}
+	}
+	return "", false
+}
+
+// isExecutable reports whether path is a regular file (following symlinks) that may be
+// executed. Outside Windows one of the executable bits must be set.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	return runtime.GOOS == "windows" || info.Mode().Perm()&0111 != 0
+}
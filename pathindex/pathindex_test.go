@@ -0,0 +1,130 @@
+package pathindex
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file with the given permissions
+func writeFile(t *testing.T, path string, perm os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"), perm); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+	// WriteFile is subject to the umask
+	if err := os.Chmod(path, perm); err != nil {
+		t.Fatalf("Failed to chmod %s: %v", path, err)
+	}
+}
+
+// touchDir sets the modification time of dir, as adding a file would
+func touchDir(t *testing.T, dir string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(dir, mtime, mtime); err != nil {
+		t.Fatalf("Failed to set mtime of %s: %v", dir, err)
+	}
+}
+
+// TestIndex tests executable detection and first-wins precedence
+func TestIndex(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits are not used on Windows")
+	}
+
+	first := t.TempDir()
+	second := t.TempDir()
+	writeFile(t, filepath.Join(first, "tool"), 0755)
+	writeFile(t, filepath.Join(first, "notes.txt"), 0644)
+	writeFile(t, filepath.Join(first, ".hidden"), 0755)
+	writeFile(t, filepath.Join(second, "tool"), 0755)
+	writeFile(t, filepath.Join(second, "other"), 0700)
+	if err := os.Mkdir(filepath.Join(second, "subdir"), 0755); err != nil {
+		t.Fatalf("Failed to create subdir: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(second, "other"), filepath.Join(second, "link")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(second, "missing"), filepath.Join(second, "dangling")); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+
+	pathList := strings.Join([]string{first, "", "relative/bin", second, first, filepath.Join(first, "missing")}, string(os.PathListSeparator))
+	index := New(pathList)
+
+	expected := []string{"link", "other", "tool"}
+	if names := index.Names(); !reflect.DeepEqual(names, expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	if path, _ := index.Lookup("tool"); path != filepath.Join(first, "tool") {
+		t.Errorf("Expected the first PATH entry to win, got %s", path)
+	}
+	if _, ok := index.Lookup("notes.txt"); ok {
+		t.Error("Expected files without executable bits to be skipped")
+	}
+
+	cmd, ok := index.GetCommand("other")
+	if !ok || cmd.Path != filepath.Join(second, "other") || !cmd.AllowArgs || cmd.Args == nil {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+}
+
+// TestIndexRescansChangedDirectories tests that a directory is rescanned when its mtime changes
+func TestIndexRescansChangedDirectories(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executable bits are not used on Windows")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "old"), 0755)
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	touchDir(t, dir, mtime)
+
+	index := New(dir)
+	if names := index.Names(); !reflect.DeepEqual(names, []string{"old"}) {
+		t.Fatalf("Expected [old], got %v", names)
+	}
+
+	// A new file that leaves the mtime untouched is not noticed
+	writeFile(t, filepath.Join(dir, "new"), 0755)
+	touchDir(t, dir, mtime)
+	if _, ok := index.Lookup("new"); ok {
+		t.Fatal("Expected the index to be reused while the directory is unchanged")
+	}
+
+	touchDir(t, dir, mtime.Add(time.Minute))
+	if _, ok := index.Lookup("new"); !ok {
+		t.Error("Expected the directory to be rescanned after its mtime changed")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "old")); err != nil {
+		t.Fatalf("Failed to remove file: %v", err)
+	}
+	touchDir(t, dir, mtime.Add(2*time.Minute))
+	if _, ok := index.Lookup("old"); ok {
+		t.Error("Expected the removed executable to disappear")
+	}
+}
+
+// TestGetCommandEscapesBraces tests that braces and $ signs in paths are not taken for
+// placeholders or environment variables
+func TestGetCommandEscapesBraces(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables need a PATHEXT extension on Windows")
+	}
+
+	dir := filepath.Join(t.TempDir(), "{bin}$HOME")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "tool"), 0755)
+
+	cmd, ok := New(dir).GetCommand("tool")
+	if !ok || !strings.Contains(cmd.Path, "{{bin}}$$HOME") {
+		t.Errorf("Expected braces and $ signs to be escaped, got %+v", cmd)
+	}
+}